@@ -0,0 +1,80 @@
+package flownet
+
+// An arcGraph is a compact, array-based representation of the residual network of a FlowNetwork, which is
+// compiled from the FlowNetwork just before solving. Each pair of adjacent nodes is joined by a forward
+// and a reverse arc, stored contiguously by the node they leave. Solving on an arcGraph avoids the hash
+// lookups needed to find the capacity and flow of an edge in the FlowNetwork itself.
+type arcGraph struct {
+	// first stores the index of the first arc leaving each node; the arcs leaving node u are those with
+	// indices in [first[u], first[u+1]).
+	first []int
+	// head stores the node each arc enters.
+	head []int32
+	// reverse stores the index of the reverse of each arc.
+	reverse []int32
+	// residual stores the residual capacity of each arc.
+	residual []int64
+}
+
+// numNodes returns the number of nodes in the graph, including the source and sink.
+func (a *arcGraph) numNodes() int {
+	return len(a.first) - 1
+}
+
+// push sends delta units of flow along the provided arc.
+func (a *arcGraph) push(arc int, delta int64) {
+	a.residual[arc] -= delta
+	a.residual[a.reverse[arc]] += delta
+}
+
+// compile builds an arcGraph from the residual network of g, visiting the arcs leaving each node in the same
+// order as the adjacency visit list. prepare must be called first.
+func (g *FlowNetwork) compile() *arcGraph {
+	n := g.numInternalNodes()
+	a := &arcGraph{first: make([]int, n+1)}
+	for u := 0; u < n; u++ {
+		a.first[u+1] = a.first[u] + len(g.adjacencyVisitList[u])
+	}
+	numArcs := a.first[n]
+	a.head = make([]int32, numArcs)
+	a.reverse = make([]int32, numArcs)
+	a.residual = make([]int64, numArcs)
+
+	unpaired := make(map[edge]int32) // arcs whose reverse has not been seen yet.
+	for u := 0; u < n; u++ {
+		for i, v := range g.adjacencyVisitList[u] {
+			arc := int32(a.first[u] + i)
+			a.head[arc] = int32(v)
+			a.residual[arc] = g.residual(edge{u, v})
+			if rev, ok := unpaired[edge{v, u}]; ok {
+				a.reverse[arc], a.reverse[rev] = rev, arc
+				delete(unpaired, edge{v, u})
+			} else {
+				unpaired[edge{u, v}] = arc
+			}
+		}
+	}
+	return a
+}
+
+// writeBack stores the flow found in the provided arcGraph, which must have been compiled from g, back into
+// the preflow of g.
+func (g *FlowNetwork) writeBack(a *arcGraph) {
+	for u := 0; u < a.numNodes(); u++ {
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			e := edge{u, int(a.head[arc])}
+			if _, ok := g.capacity[e]; !ok {
+				continue
+			}
+			capacity := g.effectiveCapacity(e)
+			// the flow along e is its capacity less its residual, unless the flow runs in reverse.
+			flow := capacity - a.residual[arc]
+			if flow < 0 {
+				flow = 0
+			}
+			if _, ok := g.preflow[e]; ok || flow > 0 {
+				g.preflow[e] = flow
+			}
+		}
+	}
+}
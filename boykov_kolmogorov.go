@@ -0,0 +1,280 @@
+package flownet
+
+// BoykovKolmogorov finds a maximum flow via the Boykov-Kolmogorov algorithm. Two search trees are grown
+// through the residual network, one rooted at the source and one at the sink. Whenever the trees touch,
+// flow is augmented along the path joining them, and any nodes cut off from their tree by saturated
+// edges are adopted by a new parent or freed. The algorithm performs especially well on grid-structured
+// networks such as those which arise in image segmentation, where every node is connected to both the
+// source and the sink.
+//
+// The search trees are kept between calls. If BoykovKolmogorov is called again after capacities have been
+// changed, the existing flow and search trees are reused. Any edge whose capacity has dropped below its flow
+// is repaired first, as in Resolve.
+//
+// An error wrapping ErrCapacityOverflow or ErrUnboundedFlow is returned as by Solve, in which case no flow
+// is found.
+func (g *FlowNetwork) BoykovKolmogorov() error {
+	if err := g.prepare(); err != nil {
+		g.clearFlow()
+		g.searchTrees = nil
+		return err
+	}
+	resume := g.searchTrees != nil && g.repairFlow()
+	if !resume {
+		g.clearFlow()
+		g.searchTrees = newSearchTrees(g.numInternalNodes())
+	}
+	a := g.compile()
+	if resume {
+		g.searchTrees.resume(a)
+	}
+	g.searchTrees.run(a)
+	g.writeBack(a)
+	return nil
+}
+
+const (
+	freeNode int8 = iota
+	sourceTree
+	sinkTree
+)
+
+// orphan is the parent of any node which has been cut off from its search tree.
+const orphan = -1
+
+// searchTrees stores the state of the Boykov-Kolmogorov algorithm, so that it can be reused.
+type searchTrees struct {
+	// tree stores which search tree each node belongs to, if any.
+	tree []int8
+	// parent stores the parent of each node in its search tree.
+	parent []int
+	// parentArc stores the arc leading from each node to its parent, in the arcGraph being solved.
+	parentArc []int
+	// active is a FIFO queue of nodes at the boundary of a search tree. Entries may be stale.
+	active   []int
+	isActive []bool
+	// orphans is a queue of nodes awaiting adoption.
+	orphans []int
+	// timestamp and dist record the last time each node was known to be connected to the root of its
+	// tree, and its distance from the root at that time.
+	timestamp []int
+	dist      []int
+	time      int
+}
+
+func newSearchTrees(numNodes int) *searchTrees {
+	t := &searchTrees{}
+	t.grow(numNodes)
+	t.tree[sourceID] = sourceTree
+	t.tree[sinkID] = sinkTree
+	t.activate(sourceID)
+	t.activate(sinkID)
+	return t
+}
+
+// grow makes room for any nodes which have been added since the search trees were built.
+func (t *searchTrees) grow(numNodes int) {
+	for len(t.tree) < numNodes {
+		t.tree = append(t.tree, freeNode)
+		t.parent = append(t.parent, orphan)
+		t.parentArc = append(t.parentArc, -1)
+		t.isActive = append(t.isActive, false)
+		t.timestamp = append(t.timestamp, 0)
+		t.dist = append(t.dist, 0)
+	}
+}
+
+// resume prepares search trees left by a previous run for use with the current capacities, in an arcGraph
+// compiled since that run. Nodes whose edge to their parent has become saturated are adopted or freed, and
+// every remaining tree node becomes active, since any of them may now have new residual edges.
+func (t *searchTrees) resume(a *arcGraph) {
+	t.grow(a.numNodes())
+	t.time++
+	for u := 2; u < len(t.tree); u++ {
+		if t.tree[u] == freeNode || t.parent[u] == orphan {
+			continue
+		}
+		t.parentArc[u] = -1
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			if int(a.head[arc]) == t.parent[u] {
+				t.parentArc[u] = arc
+				break
+			}
+		}
+		if t.parentArc[u] < 0 || t.treeResidual(a, t.tree[u], t.parentArc[u]) <= 0 {
+			t.parent[u] = orphan
+			t.orphans = append(t.orphans, u)
+		}
+	}
+	t.adopt(a)
+	for u := range t.tree {
+		if t.tree[u] != freeNode {
+			t.activate(u)
+		}
+	}
+}
+
+// run grows the search trees and augments flow along the paths found until none remain.
+func (t *searchTrees) run(a *arcGraph) {
+	for {
+		arc, ok := t.growth(a)
+		if !ok {
+			return
+		}
+		t.time++
+		t.augment(a, arc)
+		t.adopt(a)
+	}
+}
+
+// growth expands the search trees from their active nodes until they touch, returning an arc from a node in
+// the source tree to a node in the sink tree with positive residual capacity.
+func (t *searchTrees) growth(a *arcGraph) (int, bool) {
+	for len(t.active) > 0 {
+		u := t.active[0]
+		if t.tree[u] != freeNode {
+			for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+				v, back := int(a.head[arc]), int(a.reverse[arc])
+				if t.treeResidual(a, t.tree[u], back) <= 0 {
+					continue
+				}
+				if t.tree[v] == freeNode {
+					t.tree[v] = t.tree[u]
+					t.parent[v] = u
+					t.parentArc[v] = back
+					t.timestamp[v] = t.timestamp[u]
+					t.dist[v] = t.dist[u] + 1
+					t.activate(v)
+				} else if t.tree[v] != t.tree[u] {
+					if t.tree[u] == sourceTree {
+						return arc, true
+					}
+					return back, true
+				}
+			}
+		}
+		t.active = t.active[1:]
+		t.isActive[u] = false
+	}
+	return 0, false
+}
+
+// augment pushes as much flow as possible along the path from the source to the node the provided arc
+// leaves, across the arc, and from the node it enters to the sink. Nodes whose edge to their parent becomes
+// saturated are orphaned.
+func (t *searchTrees) augment(a *arcGraph, arc int) {
+	p, q := int(a.head[a.reverse[arc]]), int(a.head[arc])
+	bottleneck := a.residual[arc]
+	for u := p; u != sourceID; u = t.parent[u] {
+		bottleneck = min64(bottleneck, a.residual[a.reverse[t.parentArc[u]]])
+	}
+	for v := q; v != sinkID; v = t.parent[v] {
+		bottleneck = min64(bottleneck, a.residual[t.parentArc[v]])
+	}
+	a.push(arc, bottleneck)
+	for u := p; u != sourceID; {
+		parent, down := t.parent[u], int(a.reverse[t.parentArc[u]])
+		a.push(down, bottleneck)
+		if a.residual[down] == 0 {
+			t.parent[u] = orphan
+			t.orphans = append(t.orphans, u)
+		}
+		u = parent
+	}
+	for v := q; v != sinkID; {
+		parent, up := t.parent[v], t.parentArc[v]
+		a.push(up, bottleneck)
+		if a.residual[up] == 0 {
+			t.parent[v] = orphan
+			t.orphans = append(t.orphans, v)
+		}
+		v = parent
+	}
+}
+
+// adopt finds a new parent in the same search tree for each orphan. Orphans which cannot be adopted are
+// freed, and their children become orphans in turn.
+func (t *searchTrees) adopt(a *arcGraph) {
+	for len(t.orphans) > 0 {
+		u := t.orphans[0]
+		t.orphans = t.orphans[1:]
+
+		bestArc, bestDist := -1, -1
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			v := int(a.head[arc])
+			if t.tree[v] != t.tree[u] || t.treeResidual(a, t.tree[u], arc) <= 0 {
+				continue
+			}
+			if d := t.rootDistance(v); d >= 0 && (bestDist < 0 || d < bestDist) {
+				bestArc, bestDist = arc, d
+			}
+		}
+		if bestArc >= 0 {
+			t.parent[u] = int(a.head[bestArc])
+			t.parentArc[u] = bestArc
+			t.timestamp[u] = t.time
+			t.dist[u] = bestDist + 1
+			continue
+		}
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			v := int(a.head[arc])
+			if t.tree[v] != t.tree[u] {
+				continue
+			}
+			if t.treeResidual(a, t.tree[u], arc) > 0 {
+				t.activate(v)
+			}
+			if t.parent[v] == u {
+				t.parent[v] = orphan
+				t.orphans = append(t.orphans, v)
+			}
+		}
+		t.tree[u] = freeNode
+	}
+}
+
+// rootDistance returns the distance from nodeID to the root of its search tree, or -1 if the path to the
+// root passes through an orphan. Every node on a path found to the root is timestamped.
+func (t *searchTrees) rootDistance(nodeID int) int {
+	d := 0
+	for u := nodeID; ; u = t.parent[u] {
+		if t.timestamp[u] == t.time {
+			d += t.dist[u]
+			break
+		}
+		if u == sourceID || u == sinkID {
+			t.timestamp[u] = t.time
+			t.dist[u] = 0
+			break
+		}
+		if t.parent[u] == orphan {
+			return -1
+		}
+		d++
+	}
+	result := d
+	for u := nodeID; t.timestamp[u] != t.time; u = t.parent[u] {
+		t.timestamp[u] = t.time
+		t.dist[u] = d
+		d--
+	}
+	return result
+}
+
+// treeResidual returns the residual capacity available to the provided search tree between a child and its
+// parent, given the arc leading from the child to the parent. Flow moves away from the root of the source
+// tree and toward the root of the sink tree.
+func (t *searchTrees) treeResidual(a *arcGraph, tree int8, arc int) int64 {
+	if tree == sinkTree {
+		return a.residual[arc]
+	}
+	return a.residual[a.reverse[arc]]
+}
+
+// activate adds nodeID to the queue of active nodes.
+func (t *searchTrees) activate(nodeID int) {
+	if !t.isActive[nodeID] {
+		t.isActive[nodeID] = true
+		t.active = append(t.active, nodeID)
+	}
+}
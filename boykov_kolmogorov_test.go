@@ -0,0 +1,129 @@
+package flownet_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestBoykovKolmogorovAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if _, err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
+				t.Error(err)
+			}
+		}
+		graph.BoykovKolmogorov()
+		outflow := graph.Outflow()
+		if instance.expectedFlow != -1 && instance.expectedFlow != outflow {
+			t.Errorf("failed test %s expected max-flow of %d but was %d", path, instance.expectedFlow, outflow)
+		}
+		if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestBoykovKolmogorovGrids(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for idx := 0; idx < 20; idx++ {
+		bk, dinic, _ := randomGrid(r, 2+r.Intn(15), 2+r.Intn(15))
+		bk.BoykovKolmogorov()
+		dinic.Dinic()
+		if bk.Outflow() != dinic.Outflow() {
+			t.Errorf("test #%d: Boykov-Kolmogorov found max flow of %d but Dinic found %d", idx, bk.Outflow(), dinic.Outflow())
+		}
+		if err := flownet.SanityChecks.FlowNetwork(bk, true); err != nil {
+			t.Errorf("test #%d: sanity checks failed: %v", idx, err)
+		}
+	}
+}
+
+func TestBoykovKolmogorov_Rerun(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for idx := 0; idx < 20; idx++ {
+		width, height := 2+r.Intn(10), 2+r.Intn(10)
+		bk, dinic, terminals := randomGrid(r, width, height)
+		bk.BoykovKolmogorov()
+		for i := 0; i < 5; i++ {
+			// change the capacities of some terminal edges; some of these will drop below their flow.
+			node := r.Intn(width * height)
+			cap := r.Int63n(30)
+			id := terminals[node][r.Intn(2)]
+			bk.SetCapacity(id, cap)
+			dinic.SetCapacity(id, cap)
+			bk.BoykovKolmogorov()
+			dinic.Dinic()
+			if bk.Outflow() != dinic.Outflow() {
+				t.Errorf("test #%d, change #%d: Boykov-Kolmogorov found max flow of %d but Dinic found %d", idx, i, bk.Outflow(), dinic.Outflow())
+			}
+			if err := flownet.SanityChecks.FlowNetwork(bk, true); err != nil {
+				t.Errorf("test #%d, change #%d: sanity checks failed: %v", idx, i, err)
+			}
+		}
+	}
+}
+
+func TestBoykovKolmogorov_RerunNewEdges(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for idx := 0; idx < 20; idx++ {
+		width, height := 2+r.Intn(10), 2+r.Intn(10)
+		bk, dinic, _ := randomGrid(r, width, height)
+		bk.BoykovKolmogorov()
+		for i := 0; i < 5; i++ {
+			// join a new node and some diagonal edges to the grid, so the search trees meet new arcs.
+			node := bk.AddNode()
+			dinic.AddNode()
+			from, to, cap := r.Intn(width*height), r.Intn(width*height), r.Int63n(20)
+			for _, e := range [][2]int{{flownet.Source, node}, {node, from}, {to, node}, {node, flownet.Sink}} {
+				bk.AddEdge(e[0], e[1], cap)
+				dinic.AddEdge(e[0], e[1], cap)
+			}
+			if from != to {
+				bk.AddEdge(from, to, cap)
+				dinic.AddEdge(from, to, cap)
+			}
+			bk.BoykovKolmogorov()
+			dinic.Dinic()
+			if bk.Outflow() != dinic.Outflow() {
+				t.Errorf("test #%d, change #%d: Boykov-Kolmogorov found max flow of %d but Dinic found %d", idx, i, bk.Outflow(), dinic.Outflow())
+			}
+			if err := flownet.SanityChecks.FlowNetwork(bk, true); err != nil {
+				t.Errorf("test #%d, change #%d: sanity checks failed: %v", idx, i, err)
+			}
+		}
+	}
+}
+
+// randomGrid constructs two identical 4-connected grids, where each node is connected to the source and
+// the sink with random capacities. The IDs of the edges joining each node to the source and the sink are
+// returned as well; they are the same in both grids.
+func randomGrid(r *rand.Rand, width, height int) (flownet.FlowNetwork, flownet.FlowNetwork, [][2]flownet.EdgeID) {
+	a, b := flownet.NewFlowNetwork(width*height), flownet.NewFlowNetwork(width*height)
+	addEdge := func(from, to int, cap int64) flownet.EdgeID {
+		id, _ := a.AddEdge(from, to, cap)
+		b.AddEdge(from, to, cap)
+		return id
+	}
+	terminals := make([][2]flownet.EdgeID, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			node := y*width + x
+			terminals[node][0] = addEdge(flownet.Source, node, r.Int63n(20))
+			terminals[node][1] = addEdge(node, flownet.Sink, r.Int63n(20))
+			if x+1 < width {
+				addEdge(node, node+1, r.Int63n(10))
+				addEdge(node+1, node, r.Int63n(10))
+			}
+			if y+1 < height {
+				addEdge(node, node+width, r.Int63n(10))
+				addEdge(node+width, node, r.Int63n(10))
+			}
+		}
+	}
+	return a, b, terminals
+}
@@ -0,0 +1,89 @@
+package flownet
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Infinite is a capacity which no amount of flow can exceed. The source and sink edges which are managed
+// automatically have infinite capacity. Adding any capacity to Infinite yields Infinite, and the residual
+// capacity of an edge with infinite capacity is always Infinite, however much flow it carries.
+const Infinite int64 = math.MaxInt64
+
+// ErrCapacityOverflow is returned when capacities in a network are too large to be combined without
+// overflowing an int64. Errors returned for this reason wrap ErrCapacityOverflow and say where the
+// overflow occurred.
+var ErrCapacityOverflow = errors.New("capacity overflow")
+
+// ErrUnboundedFlow is returned when a path made up entirely of edges with infinite capacity joins the
+// source to the sink, so that no maximum flow exists.
+var ErrUnboundedFlow = errors.New("flow is unbounded: a path of edges with infinite capacity joins the source to the sink")
+
+// maxFiniteCapacity is the largest total of finite capacities which a network may contain. It ensures that
+// the capacities of any two edges can be added to one another, even once Infinite is replaced by a finite
+// stand-in.
+const maxFiniteCapacity = math.MaxInt64/2 - 1
+
+// addCapacity returns x + y, or Infinite if either x or y is Infinite. Returns false if the sum of two
+// finite capacities overflows.
+func addCapacity(x, y int64) (int64, bool) {
+	if x == Infinite || y == Infinite {
+		return Infinite, true
+	}
+	if x > Infinite-y {
+		return 0, false
+	}
+	return x + y, true
+}
+
+// effectiveCapacity returns the capacity of the provided edge, with Infinite replaced by a finite
+// stand-in which exceeds the capacity of every cut made only of finite edges. checkCapacities must have
+// been called first.
+func (g FlowNetwork) effectiveCapacity(e edge) int64 {
+	if capacity := g.capacity[e]; capacity != Infinite {
+		return capacity
+	}
+	return g.infinity
+}
+
+// checkCapacities ensures that no arithmetic performed while solving can overflow, and chooses the finite
+// stand-in used in place of Infinite. Capacities of automatically managed source edges are not counted,
+// since they are derived from the others.
+func (g *FlowNetwork) checkCapacities() error {
+	total := int64(0)
+	for e, capacity := range g.capacity {
+		if capacity == Infinite || (e.from == sourceID && !g.manualSource) {
+			continue
+		}
+		sum, ok := addCapacity(total, capacity)
+		if !ok || sum > maxFiniteCapacity {
+			return fmt.Errorf("%w: the total of all finite capacities must be at most %d", ErrCapacityOverflow, int64(maxFiniteCapacity))
+		}
+		total = sum
+	}
+	g.infinity = total + 1
+	return nil
+}
+
+// checkBounded returns ErrUnboundedFlow if a path of edges with infinite capacity joins the source to
+// the sink.
+func (g *FlowNetwork) checkBounded() error {
+	visited := make(map[int]struct{})
+	frontier := []int{sourceID}
+	for len(frontier) > 0 {
+		u := frontier[0]
+		frontier = frontier[1:]
+		for v := range g.adjacencyList[u] {
+			if _, ok := visited[v]; ok || g.capacity[edge{u, v}] != Infinite {
+				continue
+			}
+			if v == sinkID {
+				return ErrUnboundedFlow
+			}
+			visited[v] = struct{}{}
+			frontier = append(frontier, v)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package flownet_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestInfinite_Edges(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(flownet.Source, 0, flownet.Infinite)
+	graph.AddEdge(0, 1, flownet.Infinite)
+	graph.AddEdge(1, 2, 7)
+	graph.AddEdge(2, flownet.Sink, flownet.Infinite)
+
+	solvers := map[string]func() error{
+		"PushRelabel":             graph.Solve,
+		"PushRelabelHighestLabel": graph.PushRelabelHighestLabel,
+		"Dinic":                   graph.Dinic,
+		"BoykovKolmogorov":        graph.BoykovKolmogorov,
+	}
+	for name, solve := range solvers {
+		if err := solve(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if graph.Outflow() != 7 {
+			t.Errorf("%s: expected outflow of 7, found %d", name, graph.Outflow())
+		}
+		if graph.Residual(0, 1) != flownet.Infinite {
+			t.Errorf("%s: expected infinite residual along an infinite edge, found %d", name, graph.Residual(0, 1))
+		}
+		if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+}
+
+func TestInfinite_Unbounded(t *testing.T) {
+	graph := flownet.NewFlowNetwork(2)
+	graph.AddEdge(0, 1, flownet.Infinite)
+	if err := graph.Solve(); !errors.Is(err, flownet.ErrUnboundedFlow) {
+		t.Fatalf("expected ErrUnboundedFlow, found %v", err)
+	}
+	if err := graph.Dinic(); !errors.Is(err, flownet.ErrUnboundedFlow) {
+		t.Errorf("expected ErrUnboundedFlow from Dinic, found %v", err)
+	}
+	if graph.Outflow() != 0 {
+		t.Errorf("expected no flow to be found, found %d", graph.Outflow())
+	}
+}
+
+func TestCapacityOverflow(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(0, 2, math.MaxInt64-1)
+	graph.AddEdge(1, 2, math.MaxInt64-1)
+	if err := graph.Solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Fatalf("expected ErrCapacityOverflow, found %v", err)
+	}
+	if err := graph.PushRelabelHighestLabel(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow from PushRelabelHighestLabel, found %v", err)
+	}
+	if graph.Outflow() != 0 {
+		t.Errorf("expected no flow to be found, found %d", graph.Outflow())
+	}
+}
+
+func TestCapacityOverflow_Large(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(0, 2, math.MaxInt64/8)
+	graph.AddEdge(1, 2, math.MaxInt64/8)
+	if err := graph.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.Outflow() != 2*(math.MaxInt64/8) {
+		t.Errorf("expected outflow of %d, found %d", 2*(math.MaxInt64/8), graph.Outflow())
+	}
+}
+
+func TestCapacityOverflow_Solvers(t *testing.T) {
+	// the flow into node 2 is more than any finite capacity can carry, so every solver reports an overflow.
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(flownet.Source, 0, math.MaxInt64/2-1)
+	graph.AddEdge(flownet.Source, 1, math.MaxInt64/2-1)
+	graph.AddEdge(0, 2, math.MaxInt64/2-1)
+	graph.AddEdge(1, 2, math.MaxInt64/2-1)
+	graph.AddEdge(2, flownet.Sink, math.MaxInt64/2-1)
+	solvers := map[string]func() error{
+		"Solve":                   graph.Solve,
+		"PushRelabelHighestLabel": graph.PushRelabelHighestLabel,
+		"Dinic":                   graph.Dinic,
+		"BoykovKolmogorov":        graph.BoykovKolmogorov,
+		"Resolve":                 graph.Resolve,
+	}
+	for name, solve := range solvers {
+		if err := solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+			t.Errorf("%s: expected ErrCapacityOverflow, found %v", name, err)
+		}
+		if graph.Outflow() != 0 {
+			t.Errorf("%s: expected no flow to be found, found %d", name, graph.Outflow())
+		}
+	}
+}
+
+func TestCapacityOverflow_Circulation(t *testing.T) {
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, math.MaxInt64-1, math.MaxInt64-1)
+	c.AddEdge(2, 1, math.MaxInt64-1, math.MaxInt64-1)
+	solvers := map[string]func() error{
+		"Solve":                   c.Solve,
+		"PushRelabelHighestLabel": c.PushRelabelHighestLabel,
+		"Dinic":                   c.Dinic,
+		"BoykovKolmogorov":        c.BoykovKolmogorov,
+		"Resolve":                 c.Resolve,
+	}
+	for name, solve := range solvers {
+		if err := solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+			t.Errorf("%s: expected ErrCapacityOverflow, found %v", name, err)
+		}
+		if c.SatisfiesDemand() {
+			t.Errorf("%s: expected demand not to be satisfied", name)
+		}
+	}
+}
+
+func TestInfinite_Circulation(t *testing.T) {
+	c := flownet.NewCirculation(2)
+	if err := c.AddEdge(0, 1, flownet.Infinite, 3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c.AddEdge(1, 0, 5, 0)
+	if c.Capacity(0, 1) != flownet.Infinite {
+		t.Errorf("expected infinite capacity, found %d", c.Capacity(0, 1))
+	}
+	if err := c.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() {
+		t.Errorf("expected demand to be satisfied")
+	}
+}
@@ -1,11 +1,8 @@
-// Package flownet provides algorithms for solving maximum-flow and circulation with node and/or edge demands.
-// It implements a push-relabel algorithm for maximum flow and provides a wrapper for specifying circulation
-// problems.
 package flownet
 
 import (
+	"context"
 	"fmt"
-	"math"
 )
 
 // A Circulation is a flow network which has an additional demand associated with each of its nodes
@@ -21,12 +18,23 @@ type Circulation struct {
 	demand map[edge]int64
 	// nodeDemand stores the demand for each node
 	nodeDemand map[int]int64
+	// cost stores the cost per unit of flow along each edge
+	cost map[edge]int64
 	// special source node used for node demands
 	nodeSource int
 	// special sink node used for node demands
 	nodeSink int
 	// amount of flow expected in a valid circulation.
 	targetValue int64
+	// absorption stores the most flow which each node may absorb without sending it on, by external ID.
+	// A node listed here also absorbs the flow which meets the demands of the edges entering it. Transshipments
+	// absorb stored flow this way.
+	absorption map[int]int64
+	// basis stores the state of each edge which is in the spanning tree or saturated, as found by the last
+	// call to NetworkSimplex, keyed by internal IDs.
+	basis map[edge]simplexState
+	// potential stores the potential of each node found by the last call to NetworkSimplex, by internal ID.
+	potential []int64
 }
 
 // NewCirculation constructs a new graph allocating initial capacity for the provided number of nodes.
@@ -37,9 +45,30 @@ func NewCirculation(numNodes int) Circulation {
 		demand: make(map[edge]int64),
 		// nodeDemand maps from external nodeIDs to the demand for each node.
 		nodeDemand: make(map[int]int64),
+		// cost maps from edges (using external nodeIDs) to the cost per unit of flow along each edge.
+		cost: make(map[edge]int64),
 	}
 }
 
+// AddNode adds a new node to the circulation and returns its ID, which must be used in subsequent calls.
+func (c *Circulation) AddNode() int {
+	id := c.FlowNetwork.AddNode()
+	// hidden nodes move up to make room for the new node, and the basis found by NetworkSimplex moves with
+	// them.
+	u := internalID(id)
+	if u < len(c.potential) {
+		c.potential = append(c.potential[:u], append([]int64{0}, c.potential[u:]...)...)
+	}
+	if len(c.inHalf) > 0 && c.basis != nil {
+		basis := make(map[edge]simplexState, len(c.basis))
+		for e, state := range c.basis {
+			basis[edge{shifted(e.from, u), shifted(e.to, u)}] = state
+		}
+		c.basis = basis
+	}
+	return id
+}
+
 // SetNodeDemand sets the demand for a node.
 func (c *Circulation) SetNodeDemand(nodeID int, demand int64) error {
 	if nodeID == Source || nodeID == Sink {
@@ -48,28 +77,32 @@ func (c *Circulation) SetNodeDemand(nodeID int, demand int64) error {
 	if nodeID < 0 || nodeID >= c.numNodes {
 		return fmt.Errorf("no node with id %d is known", nodeID)
 	}
+	if demand == Infinite || demand <= -Infinite {
+		return fmt.Errorf("node demands must be finite")
+	}
 	if demand != 0 && c.nodeSource == 0 {
 		c.nodeSource = c.AddNode()
 		c.nodeSink = c.AddNode()
-		c.FlowNetwork.AddEdge(c.nodeSink, c.nodeSource, math.MaxInt64)
+		c.setEdge(c.nodeSink, c.nodeSource, Infinite)
 	}
-	if demand == 0 {
-		c.FlowNetwork.AddEdge(c.nodeSource, nodeID, 0)
-		c.FlowNetwork.AddEdge(nodeID, c.nodeSink, 0)
+	if demand == 0 && c.nodeSource != 0 {
+		c.setEdge(c.nodeSource, nodeID, 0)
+		c.setEdge(nodeID, c.nodeSink, 0)
 	}
 	if demand > 0 {
-		c.FlowNetwork.AddEdge(nodeID, c.nodeSink, demand)
+		c.setEdge(nodeID, c.nodeSink, demand)
 	}
 	if demand < 0 {
-		c.FlowNetwork.AddEdge(c.nodeSource, nodeID, -demand)
+		c.setEdge(c.nodeSource, nodeID, -demand)
 	}
 	c.nodeDemand[nodeID] = demand
 	return nil
 }
 
 // AddEdge sets the capacity and non-negative demand of the edge in the circulation. An error is returned
-// if either fromID or toID are not valid node IDs. Adding an edge twice has no additional effect.
-// Setting demands on edges also updates the demand on the adjacent nodes.
+// if either fromID or toID are not valid node IDs. Adding an edge twice has no additional effect; unlike
+// in a FlowNetwork, parallel edges are not supported. Setting demands on edges also updates the demand on
+// the adjacent nodes. An edge with Infinite capacity keeps its infinite capacity whatever its demand.
 func (c *Circulation) AddEdge(fromID, toID int, capacity, demand int64) error {
 	if fromID == Source || fromID == Sink || toID == Source || toID == Sink {
 		// TODO: could source/sink be interpreted as the 'special' nodeSource / nodeSink?
@@ -78,10 +111,16 @@ func (c *Circulation) AddEdge(fromID, toID int, capacity, demand int64) error {
 	if demand < 0 {
 		return fmt.Errorf("edge demands must be non-zero")
 	}
+	if demand == Infinite {
+		return fmt.Errorf("edge demands must be finite")
+	}
 	if capacity < demand {
 		return fmt.Errorf("capacity cannot be smaller than demand; capacity = %d, demand = %d", capacity, demand)
 	}
-	if err := c.FlowNetwork.AddEdge(fromID, toID, capacity-demand); err != nil {
+	if capacity != Infinite {
+		capacity -= demand
+	}
+	if err := c.setEdge(fromID, toID, capacity); err != nil {
 		return err
 	}
 	e := edge{fromID, toID}
@@ -103,7 +142,8 @@ func (c *Circulation) Underflow() int64 {
 
 // Capacity returns the capacity of the provided edge.
 func (c *Circulation) Capacity(from, to int) int64 {
-	return c.FlowNetwork.Capacity(from, to) + c.demand[edge{from, to}]
+	capacity, _ := addCapacity(c.FlowNetwork.Capacity(from, to), c.demand[edge{from, to}])
+	return capacity
 }
 
 // Flow returns the flow achieved by the circulation along the provided edge. The results are
@@ -122,18 +162,160 @@ func (c *Circulation) NodeDemand(nodeID int) int64 {
 	return c.nodeDemand[nodeID]
 }
 
+// SetEdgeCost sets the cost of sending one unit of flow along the edge from fromID to toID. Costs may be
+// negative. Costs are ignored when searching for any valid circulation, but CostScaling finds the cheapest
+// one. An error is returned if either fromID or toID are not valid node IDs.
+func (c *Circulation) SetEdgeCost(fromID, toID int, cost int64) error {
+	if fromID < 0 || fromID >= c.numNodes {
+		return fmt.Errorf("no node with id %d is known", fromID)
+	}
+	if toID < 0 || toID >= c.numNodes {
+		return fmt.Errorf("no node with id %d is known", toID)
+	}
+	if cost == 0 {
+		delete(c.cost, edge{fromID, toID})
+	} else {
+		c.cost[edge{fromID, toID}] = cost
+	}
+	return nil
+}
+
+// EdgeCost returns the cost of sending one unit of flow along the provided edge.
+func (c *Circulation) EdgeCost(from, to int) int64 {
+	return c.cost[edge{from, to}]
+}
+
+// Cost returns the total cost of the circulation found by the last solve, including the cost of the flow
+// which meets edge demands. Returns zero if the total cost cannot be stored in an int64; CostScaling and
+// NetworkSimplex return an error wrapping ErrCapacityOverflow when this happens.
+func (c *Circulation) Cost() int64 {
+	result, _ := c.totalCost()
+	return result
+}
+
+// totalCost returns the total cost of the circulation found by the last solve. Returns false if it cannot be
+// stored in an int64. Positive and negative costs are totaled apart, so the result does not depend on the
+// order in which edges are visited.
+func (c *Circulation) totalCost() (int64, bool) {
+	positive, negative := int64(0), int64(0)
+	for e, cost := range c.cost {
+		product, ok := mulCost(c.Flow(e.from, e.to), cost)
+		if !ok {
+			return 0, false
+		}
+		if product > 0 {
+			positive, ok = addCost(positive, product)
+		} else {
+			negative, ok = addCost(negative, product)
+		}
+		if !ok {
+			return 0, false
+		}
+	}
+	return positive + negative, true
+}
+
 // SatisfiesDemand is true iff the flow satisfies all of the required node and edge demands.
 func (c *Circulation) SatisfiesDemand() bool {
 	return c.Outflow() == c.targetValue
 }
 
-// PushRelabel finds a valid circulation (if one exists) via the push-relabel algorithm.
+// PushRelabel finds a valid circulation (if one exists) via the push-relabel algorithm. No flow is found if
+// the demands or capacities would overflow, or the flow is unbounded; use Solve to find out whether either
+// of these happened.
 func (c *Circulation) PushRelabel() {
-	if len(c.demand) == 0 && len(c.nodeDemand) == 0 {
-		c.FlowNetwork.PushRelabel()
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
 		return
 	}
+	c.FlowNetwork.PushRelabel()
+}
+
+// Solve finds a valid circulation (if one exists) via the same algorithm as PushRelabel, returning an error if the
+// algorithm could not be completed. Use SatisfiesDemand to check whether a valid circulation was found.
+func (c *Circulation) Solve() error {
+	return c.SolveContext(context.Background(), SolveOptions{})
+}
+
+// SolveContext finds a valid circulation (if one exists) via the same algorithm as PushRelabel, stopping early
+// if the context is done. See FlowNetwork.SolveContext for details.
+func (c *Circulation) SolveContext(ctx context.Context, opts SolveOptions) error {
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	return c.FlowNetwork.SolveContext(ctx, opts)
+}
+
+// Dinic finds a valid circulation (if one exists) via Dinic's algorithm. Errors are returned as by Solve; use
+// SatisfiesDemand to check whether a valid circulation was found.
+func (c *Circulation) Dinic() error {
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	return c.FlowNetwork.Dinic()
+}
+
+// PushRelabelHighestLabel finds a valid circulation (if one exists) via the highest-label variant of the
+// push-relabel algorithm. Errors are returned as by Solve.
+func (c *Circulation) PushRelabelHighestLabel() error {
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	return c.FlowNetwork.PushRelabelHighestLabel()
+}
+
+// BoykovKolmogorov finds a valid circulation (if one exists) via the Boykov-Kolmogorov algorithm. Errors are
+// returned as by Solve.
+func (c *Circulation) BoykovKolmogorov() error {
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	return c.FlowNetwork.BoykovKolmogorov()
+}
+
+// Resolve finds a valid circulation (if one exists) starting from the flow found by the last solve. See
+// FlowNetwork.Resolve for details.
+func (c *Circulation) Resolve() error {
+	if err := c.connectDemands(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	return c.FlowNetwork.Resolve()
+}
+
+// hasDemands is true if any edge or node demand has been set, or if any node may absorb flow.
+func (c *Circulation) hasDemands() bool {
+	if len(c.demand) > 0 || len(c.nodeDemand) > 0 {
+		return true
+	}
+	for _, amount := range c.absorption {
+		if amount > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// connectDemands connects the source and sink to each node and edge with a demand, so that a maximum flow
+// in the underlying FlowNetwork is a valid circulation whenever one exists. An error wrapping
+// ErrCapacityOverflow is returned if the demands cannot be added without overflowing.
+//
+// The flow which meets an edge demand is sent to the sink from the tail of the edge, and sent on from the
+// source to its head. Supplies are met via the special source node instead, which the source sends exactly
+// the flow that the other demands need. The edges leaving the source then carry as much flow as the edges
+// entering the sink, so a flow meets every demand only if it saturates every edge joined to the source or
+// sink. Were supplies sent straight from the source, a supply could stand in for flow which cannot reach the
+// head of an edge with a demand.
+func (c *Circulation) connectDemands() error {
+	if !c.hasDemands() {
+		return nil
+	}
 	// disconnect the source and sink nodes; they don't work the same for circulations with demands
+	c.manualSource, c.manualSink = true, true
 	for edge := range c.FlowNetwork.capacity {
 		if edge.from == sourceID {
 			delete(c.FlowNetwork.capacity, edge)
@@ -142,34 +324,103 @@ func (c *Circulation) PushRelabel() {
 			delete(c.FlowNetwork.capacity, edge)
 		}
 	}
+	// until every demand has been connected, no flow can satisfy the circulation.
+	c.targetValue = Infinite
 	targetValue := int64(0)
+	// fromSupply is the flow which the special source node must send to meet every demand not met from the
+	// source. It never exceeds targetValue, so cannot overflow.
+	fromSupply := int64(0)
 	for e, demand := range c.demand {
-		if demand != 0 {
-			c.addEdge(e.from, Sink, c.Capacity(e.from, Sink)+demand)
-			c.addEdge(Source, e.to, c.Capacity(Source, e.to)+demand)
+		if demand == 0 {
+			continue
 		}
-		if demand > 0 {
-			targetValue += demand
+		// flow leaves a node with a node capacity from its out-half.
+		from := c.outHalfID(e.from)
+		toSink, ok := addCapacity(c.Capacity(from, Sink), demand)
+		if !ok {
+			return fmt.Errorf("%w: demand on edges leaving node %d", ErrCapacityOverflow, e.from)
+		}
+		c.addEdge(from, Sink, toSink)
+		if targetValue, ok = addCapacity(targetValue, demand); !ok {
+			return fmt.Errorf("%w: total demand of the circulation", ErrCapacityOverflow)
+		}
+		if _, ok := c.absorption[e.to]; ok {
+			// a node which absorbs flow sends none of this flow on, so it must come from the supplies.
+			fromSupply += demand
+			continue
 		}
+		fromSource, ok := addCapacity(c.Capacity(Source, e.to), demand)
+		if !ok {
+			return fmt.Errorf("%w: demand on edges entering node %d", ErrCapacityOverflow, e.to)
+		}
+		c.addEdge(Source, e.to, fromSource)
 	}
 
+	// node demands share their edges to the sink with any edge demands.
+	supply := int64(0)
 	for u, demand := range c.nodeDemand {
 		if demand > 0 {
-			c.addEdge(u, Sink, demand)
-			targetValue += demand
+			from := c.outHalfID(u)
+			toSink, ok := addCapacity(c.Capacity(from, Sink), demand)
+			if !ok {
+				return fmt.Errorf("%w: demand of node %d", ErrCapacityOverflow, u)
+			}
+			c.addEdge(from, Sink, toSink)
+			if targetValue, ok = addCapacity(targetValue, demand); !ok {
+				return fmt.Errorf("%w: total demand of the circulation", ErrCapacityOverflow)
+			}
+			fromSupply += demand
 		}
 		if demand < 0 {
-			c.addEdge(Source, u, -demand)
+			supply, _ = addCapacity(supply, -demand)
+		}
+	}
+	if c.nodeSource != 0 {
+		// the edges from the special source node carry each supply, and nothing else until absorbed flow is
+		// connected below.
+		for e := range c.FlowNetwork.capacity {
+			if e.from == internalID(c.nodeSource) {
+				c.FlowNetwork.capacity[e] = 0
+			}
+		}
+		for u, demand := range c.nodeDemand {
+			if demand < 0 {
+				c.addEdge(c.nodeSource, u, -demand)
+			}
 		}
 	}
 
-	if targetValue == 0 { // handle no node or edge demands
-		c.addEdge(Source, c.nodeSource, math.MaxInt64)
-		c.addEdge(c.nodeSink, Sink, math.MaxInt64)
+	// a node which absorbs flow must send it to the sink, but may instead take it straight from the supplies.
+	// No node can absorb more flow than the supplies provide.
+	for u, amount := range c.absorption {
+		amount = min64(amount, supply)
+		if amount == 0 {
+			continue
+		}
+		from := c.outHalfID(u)
+		toSink, ok := addCapacity(c.Capacity(from, Sink), amount)
+		if !ok {
+			return fmt.Errorf("%w: flow absorbed by node %d", ErrCapacityOverflow, u)
+		}
+		fromSpecial, ok := addCapacity(c.FlowNetwork.Capacity(c.nodeSource, u), amount)
+		if !ok {
+			return fmt.Errorf("%w: flow absorbed by node %d", ErrCapacityOverflow, u)
+		}
+		c.addEdge(from, Sink, toSink)
+		c.addEdge(c.nodeSource, u, fromSpecial)
+		if targetValue, ok = addCapacity(targetValue, amount); !ok {
+			return fmt.Errorf("%w: total demand of the circulation", ErrCapacityOverflow)
+		}
+		fromSupply += amount
+	}
+
+	// the special nodes only exist once some node demand has been set. Without them, there are no supplies,
+	// and any demand which needs them cannot be met.
+	if c.nodeSource != 0 {
+		c.addEdge(Source, c.nodeSource, fromSupply)
+		// flow must not also circulate through the special nodes.
 		c.addEdge(c.nodeSink, c.nodeSource, 0)
 	}
 	c.targetValue = targetValue
-
-	// find the max-flow in the resulting flow network.
-	c.FlowNetwork.PushRelabel()
+	return nil
 }
@@ -49,3 +49,91 @@ func TestSanityAllCirculations(t *testing.T) {
 		return nil
 	})
 }
+
+func TestCirculation_NodeDemandsConserveFlow(t *testing.T) {
+	// node 0 supplies 4 units and node 5 demands 4 units; every other node must pass on what it receives.
+	c := flownet.NewCirculation(6)
+	edges := []Edge{{0, 1}, {0, 2}, {1, 3}, {3, 2}, {2, 4}, {4, 1}, {4, 5}, {3, 5}}
+	for _, e := range edges {
+		c.AddEdge(e.from, e.to, 10, 0)
+	}
+	c.SetNodeDemand(0, -4)
+	c.SetNodeDemand(5, 4)
+	c.PushRelabel()
+	if !c.SatisfiesDemand() {
+		t.Fatalf("expected the demand to be satisfied")
+	}
+	netInflow := make([]int64, 6)
+	for _, e := range edges {
+		netInflow[e.from] -= c.Flow(e.from, e.to)
+		netInflow[e.to] += c.Flow(e.from, e.to)
+	}
+	for u, inflow := range netInflow {
+		if inflow != c.NodeDemand(u) {
+			t.Errorf("expected node %d to take in %d units more than it sends on, found %d", u, c.NodeDemand(u), inflow)
+		}
+	}
+}
+
+func TestCirculation_ZeroNodeDemand(t *testing.T) {
+	// a node demand of zero, set before any other node demand, must not touch the edges of the circulation.
+	c := flownet.NewCirculation(2)
+	c.AddEdge(0, 1, 5, 0)
+	c.AddEdge(1, 0, 5, 0)
+	c.SetNodeDemand(1, 0)
+	if c.Capacity(0, 1) != 5 || c.Capacity(1, 0) != 5 {
+		t.Errorf("expected capacities of 5, found %d and %d", c.Capacity(0, 1), c.Capacity(1, 0))
+	}
+	if err := c.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() {
+		t.Errorf("expected the empty circulation to satisfy the demand")
+	}
+
+	c.AddEdge(0, 1, 5, 2)
+	if err := c.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() || c.Flow(1, 0) != 2 {
+		t.Errorf("expected 2 units to return along 1 -> 0, found %d", c.Flow(1, 0))
+	}
+}
+
+func TestCirculation_NodeAndEdgeDemands(t *testing.T) {
+	// node 0 keeps the 2 units supplied by node 2, while 3 units circulate along 0 -> 1 -> 0. Both the node
+	// demand and the edge demand send flow from node 0 to the sink.
+	c := flownet.NewCirculation(3)
+	c.AddEdge(2, 0, 5, 0)
+	c.AddEdge(0, 1, 5, 3)
+	c.AddEdge(1, 0, 5, 0)
+	c.SetNodeDemand(2, -2)
+	c.SetNodeDemand(0, 2)
+	for name, solve := range map[string]func() error{"Solve": c.Solve, "Dinic": c.Dinic} {
+		if err := solve(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !c.SatisfiesDemand() {
+			t.Errorf("%s: expected the demand to be satisfied; found an underflow of %d", name, c.Underflow())
+		}
+		if err := flownet.SanityChecks.Circulation(c); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+}
+
+func TestCirculation_UnmetEdgeDemand(t *testing.T) {
+	// the demand of 1 -> 2 sends 5 units to node 2, which has no way to send them on, whatever node 0 supplies.
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 10, 0)
+	c.AddEdge(1, 2, 5, 5)
+	c.SetNodeDemand(0, -10)
+	for name, solve := range map[string]func() error{"Solve": c.Solve, "Dinic": c.Dinic} {
+		if err := solve(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if c.SatisfiesDemand() {
+			t.Errorf("%s: expected the demand not to be satisfied", name)
+		}
+	}
+}
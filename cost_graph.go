@@ -0,0 +1,150 @@
+package flownet
+
+import (
+	"container/heap"
+	"errors"
+)
+
+// ErrInfeasible is returned when no flow satisfies the requirements of a problem, such as a required flow
+// value or a set of demands. Errors returned for this reason wrap ErrInfeasible and say what could not be
+// satisfied.
+var ErrInfeasible = errors.New("no feasible flow exists")
+
+// ErrNegativeCycle is returned when a cycle of edges having positive capacity has a negative total cost,
+// so that sending flow around it forever would keep reducing the cost.
+var ErrNegativeCycle = errors.New("a cycle of edges with positive capacity has negative total cost")
+
+// A costGraph is the residual network of a flow problem in which each unit of flow along an arc has a cost.
+// Arcs are stored in pairs: arc 2i is an edge of the problem, and arc 2i+1 is its reverse, whose cost is
+// the negation of the edge's cost. The residual capacity of a reverse arc is the flow along its edge.
+type costGraph struct {
+	// out stores the indices of the arcs leaving each node.
+	out [][]int
+	// head stores the node each arc enters.
+	head []int
+	// residual stores the residual capacity of each arc. Arcs with Infinite residual capacity keep it.
+	residual []int64
+	// cost stores the cost of sending one unit of flow along each arc.
+	cost []int64
+}
+
+// newCostGraph constructs an empty costGraph with the provided number of nodes.
+func newCostGraph(numNodes int) *costGraph {
+	return &costGraph{out: make([][]int, numNodes)}
+}
+
+// addArc adds an edge from u to v carrying the provided flow, along with its reverse, and returns the index
+// of the arc for the edge.
+func (c *costGraph) addArc(u, v int, capacity, cost, flow int64) int {
+	arc := len(c.head)
+	c.out[u] = append(c.out[u], arc)
+	c.out[v] = append(c.out[v], arc+1)
+	c.head = append(c.head, v, u)
+	residual := capacity
+	if capacity != Infinite {
+		residual -= flow
+	}
+	c.residual = append(c.residual, residual, flow)
+	c.cost = append(c.cost, cost, -cost)
+	return arc
+}
+
+// numNodes returns the number of nodes in the graph.
+func (c *costGraph) numNodes() int {
+	return len(c.out)
+}
+
+// flow returns the flow along the edge whose arc is provided.
+func (c *costGraph) flow(arc int) int64 {
+	return c.residual[arc^1]
+}
+
+// push sends the provided amount of flow along an arc.
+func (c *costGraph) push(arc int, amount int64) {
+	if c.residual[arc] != Infinite {
+		c.residual[arc] -= amount
+	}
+	if c.residual[arc^1] != Infinite {
+		c.residual[arc^1] += amount
+	}
+}
+
+// potentials returns node potentials under which every arc with positive residual capacity has non-negative
+// reduced cost, found via the Bellman-Ford algorithm. Returns ErrNegativeCycle if no such potentials exist.
+func (c *costGraph) potentials() ([]int64, error) {
+	// every node starts at distance zero, as though joined to each of them by a hidden root.
+	potential := make([]int64, c.numNodes())
+	for round := 0; ; round++ {
+		changed := false
+		for u, arcs := range c.out {
+			for _, arc := range arcs {
+				if v := c.head[arc]; c.residual[arc] > 0 && potential[u]+c.cost[arc] < potential[v] {
+					potential[v] = potential[u] + c.cost[arc]
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			return potential, nil
+		}
+		if round == c.numNodes() {
+			return nil, ErrNegativeCycle
+		}
+	}
+}
+
+// shortestPaths finds the cheapest path from the provided node to every other node via Dijkstra's
+// algorithm, using arcs with positive residual capacity. Arc costs are reduced by the provided potentials,
+// under which they must be non-negative. Returns the reduced distance to each node, and the arc by which
+// each node is reached; both are -1 for nodes which cannot be reached.
+func (c *costGraph) shortestPaths(start int, potential []int64) ([]int64, []int) {
+	n := c.numNodes()
+	dist, parent := make([]int64, n), make([]int, n)
+	for u := range dist {
+		dist[u], parent[u] = -1, -1
+	}
+	done := make([]bool, n)
+	dist[start] = 0
+	h := &distanceHeap{{start, 0}}
+	for h.Len() > 0 {
+		u := heap.Pop(h).(nodeDistance).node
+		if done[u] {
+			continue
+		}
+		done[u] = true
+		for _, arc := range c.out[u] {
+			v := c.head[arc]
+			if c.residual[arc] <= 0 || done[v] {
+				continue
+			}
+			if d := dist[u] + c.cost[arc] + potential[u] - potential[v]; dist[v] < 0 || d < dist[v] {
+				dist[v], parent[v] = d, arc
+				heap.Push(h, nodeDistance{v, d})
+			}
+		}
+	}
+	return dist, parent
+}
+
+// nodeDistance is an entry in a distanceHeap.
+type nodeDistance struct {
+	node     int
+	distance int64
+}
+
+// distanceHeap stores a heap of nodes ordered by their distance.
+type distanceHeap []nodeDistance
+
+func (h distanceHeap) Len() int           { return len(h) }
+func (h distanceHeap) Less(i, j int) bool { return h[i].distance < h[j].distance }
+func (h distanceHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *distanceHeap) Push(x interface{}) {
+	*h = append(*h, x.(nodeDistance))
+}
+
+func (h *distanceHeap) Pop() interface{} {
+	x := (*h)[len(*h)-1]
+	*h = (*h)[:len(*h)-1]
+	return x
+}
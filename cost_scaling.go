@@ -0,0 +1,216 @@
+package flownet
+
+import "fmt"
+
+// costScalingFactor is the factor by which the optimality tolerance shrinks in each phase of cost scaling.
+const costScalingFactor = 8
+
+// CostScaling finds the cheapest valid circulation, using the costs set via SetEdgeCost, via the cost
+// scaling algorithm of Goldberg and Tarjan. Edge and node demands are met just as by PushRelabel.
+//
+// A valid circulation is first found via Dinic's algorithm. Costs are then multiplied by one more than the
+// number of nodes, and the circulation is refined in phases: each phase halves an optimality tolerance
+// several times over, saturates every edge whose cost reduced by the node prices is negative, and then
+// pushes the resulting excess flow back along edges of negative reduced cost, relabeling nodes as needed.
+// Once the tolerance falls to one, the circulation is the cheapest one.
+//
+// An error wrapping ErrInfeasible is returned if no valid circulation exists, and ErrNegativeCycle is
+// returned if a cycle of edges with Infinite capacity has negative total cost, so that there is no cheapest
+// circulation. Errors found while finding a valid circulation are returned as by Solve. No flow is found
+// if any of these errors is returned. An error wrapping ErrCapacityOverflow is also returned if the total
+// cost of the circulation found cannot be stored in an int64; the flow is kept, but Cost returns zero.
+func (c *Circulation) CostScaling() error {
+	if err := c.feasibleFlow(); err != nil {
+		c.clearFlow()
+		return err
+	}
+	model, err := c.costModel()
+	if err != nil {
+		c.clearFlow()
+		return err
+	}
+	model.costScaling()
+	model.writeBack(c)
+	if _, ok := c.totalCost(); !ok {
+		return fmt.Errorf("%w: the total cost of the circulation does not fit in an int64", ErrCapacityOverflow)
+	}
+	return nil
+}
+
+// feasibleFlow finds any valid circulation via Dinic's algorithm. An error wrapping ErrInfeasible is
+// returned if there is none.
+func (c *Circulation) feasibleFlow() error {
+	c.clearFlow()
+	if !c.hasDemands() {
+		// with no demands to meet, the empty circulation is valid.
+		return nil
+	}
+	if err := c.connectDemands(); err != nil {
+		return err
+	}
+	if err := c.prepare(); err != nil {
+		return err
+	}
+	c.dinic()
+	if !c.SatisfiesDemand() {
+		return fmt.Errorf("%w: %d units of demand cannot be met", ErrInfeasible, c.Underflow())
+	}
+	return nil
+}
+
+// circulationModel is the residual network of a circulation, with costs, whose arcs carry the flow of the
+// circulation less its edge demands.
+type circulationModel struct {
+	*costGraph
+	// edges stores the edge of the underlying FlowNetwork to which each pair of arcs belongs, by internal IDs.
+	edges []edge
+	// infinite is true for each edge with Infinite capacity.
+	infinite []bool
+}
+
+// costModel builds the residual network of the circulation found by the last solve, leaving out the nodes
+// given by fixedNodes. Edges between the halves of a split node cost nothing.
+// Infinite capacities are replaced by a finite stand-in larger than the total of the finite capacities,
+// including those which meet demands; some cheapest circulation never exceeds it. Returns ErrNegativeCycle
+// if a cycle of edges with Infinite capacity has negative total cost.
+func (c *Circulation) costModel() (circulationModel, error) {
+	hidden := c.fixedNodes()
+	m := circulationModel{costGraph: newCostGraph(c.numInternalNodes())}
+	var capacities, costs []int64
+	total := int64(0)
+	for e, capacity := range c.FlowNetwork.capacity {
+		// edges joined to the source or sink count towards the total, since they bound the flow which meets
+		// demands.
+		if capacity != Infinite {
+			var ok bool
+			if total, ok = addCapacity(total, capacity); !ok || total > maxFiniteCapacity {
+				return circulationModel{}, fmt.Errorf("%w: the total of all finite capacities must be at most %d", ErrCapacityOverflow, int64(maxFiniteCapacity))
+			}
+		}
+		if hidden[e.from] || hidden[e.to] {
+			continue
+		}
+		cost := int64(0)
+		if visible, ok := c.visibleEdge(e); ok {
+			cost = c.cost[edge{externalID(visible.from), externalID(visible.to)}]
+		}
+		m.edges = append(m.edges, e)
+		m.infinite = append(m.infinite, capacity == Infinite)
+		capacities, costs = append(capacities, capacity), append(costs, cost)
+	}
+	unbounded := newCostGraph(c.numInternalNodes())
+	for i, e := range m.edges {
+		if m.infinite[i] {
+			capacities[i] = total + 1
+			unbounded.addArc(e.from, e.to, Infinite, costs[i], 0)
+		}
+		m.addArc(e.from, e.to, capacities[i], costs[i], c.preflow[e])
+	}
+	if _, err := unbounded.potentials(); err != nil {
+		return circulationModel{}, err
+	}
+	return m, nil
+}
+
+// fixedNodes returns the set of nodes whose edges keep their flow in the cost model, by internal ID. The flow
+// along the edges joined to the source or sink meets every demand, so it is left as it is, and the special
+// sink node carries no flow. The special source node stays in the model, since it meets supplies, some of
+// which may go unused.
+func (c *Circulation) fixedNodes() map[int]bool {
+	fixed := c.hiddenNodes()
+	fixed[sourceID], fixed[sinkID] = true, true
+	if c.nodeSource != 0 {
+		delete(fixed, internalID(c.nodeSource))
+	}
+	return fixed
+}
+
+// writeBack stores the flow along each edge of the model into the circulation.
+func (m circulationModel) writeBack(c *Circulation) {
+	for i, e := range m.edges {
+		if flow := m.flow(2 * i); flow > 0 || c.preflow[e] != 0 {
+			c.preflow[e] = flow
+		}
+	}
+}
+
+// costScaling turns the circulation in the graph into a cheapest one. The flow must be a circulation: every
+// node must send as much flow as it receives.
+func (c *costGraph) costScaling() {
+	n := int64(c.numNodes())
+	epsilon := int64(0)
+	for arc := range c.cost {
+		c.cost[arc] *= n + 1
+		if c.cost[arc] > epsilon {
+			epsilon = c.cost[arc]
+		}
+	}
+	price := make([]int64, c.numNodes())
+	for epsilon > 1 {
+		epsilon /= costScalingFactor
+		if epsilon < 1 {
+			epsilon = 1
+		}
+		c.refine(epsilon, price)
+	}
+	for arc := range c.cost {
+		c.cost[arc] /= n + 1
+	}
+}
+
+// refine turns a circulation which is optimal to within some multiple of epsilon into one which is optimal
+// to within epsilon, meaning that no arc with positive residual capacity has a reduced cost below -epsilon.
+func (c *costGraph) refine(epsilon int64, price []int64) {
+	reducedCost := func(arc int) int64 {
+		return c.cost[arc] + price[c.head[arc^1]] - price[c.head[arc]]
+	}
+	excess := make([]int64, c.numNodes())
+	for arc, residual := range c.residual {
+		if residual > 0 && reducedCost(arc) < 0 {
+			excess[c.head[arc^1]] -= residual
+			excess[c.head[arc]] += residual
+			c.push(arc, residual)
+		}
+	}
+	var active []int
+	for u, x := range excess {
+		if x > 0 {
+			active = append(active, u)
+		}
+	}
+	current := make([]int, c.numNodes())
+	for len(active) > 0 {
+		u := active[0]
+		active = active[1:]
+		for excess[u] > 0 {
+			if current[u] == len(c.out[u]) {
+				// relabel; some arc leaving u then has reduced cost -epsilon.
+				best := int64(0)
+				found := false
+				for _, arc := range c.out[u] {
+					if c.residual[arc] > 0 {
+						if p := price[c.head[arc]] - c.cost[arc]; !found || p > best {
+							best, found = p, true
+						}
+					}
+				}
+				price[u] = best - epsilon
+				current[u] = 0
+				continue
+			}
+			arc := c.out[u][current[u]]
+			if c.residual[arc] <= 0 || reducedCost(arc) >= 0 {
+				current[u]++
+				continue
+			}
+			v := c.head[arc]
+			amount := min64(excess[u], c.residual[arc])
+			c.push(arc, amount)
+			excess[u] -= amount
+			if excess[v] <= 0 && excess[v]+amount > 0 {
+				active = append(active, v)
+			}
+			excess[v] += amount
+		}
+	}
+}
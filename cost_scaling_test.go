@@ -0,0 +1,281 @@
+package flownet_test
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestCostScaling(t *testing.T) {
+	// node 0 supplies 5 units to node 3, via a cheap route of capacity 3 or an expensive one.
+	c := flownet.NewCirculation(4)
+	c.AddEdge(0, 1, 3, 0)
+	c.AddEdge(1, 3, 10, 0)
+	c.AddEdge(0, 2, 10, 0)
+	c.AddEdge(2, 3, 10, 0)
+	c.SetEdgeCost(0, 1, 1)
+	c.SetEdgeCost(0, 2, 4)
+	c.SetNodeDemand(0, -5)
+	c.SetNodeDemand(3, 5)
+	if err := c.CostScaling(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() || c.Cost() != 3*1+2*4 {
+		t.Errorf("expected a valid circulation of cost 11, found cost %d", c.Cost())
+	}
+	if c.Flow(0, 1) != 3 || c.Flow(0, 2) != 2 {
+		t.Errorf("expected flows of 3 and 2, found %d and %d", c.Flow(0, 1), c.Flow(0, 2))
+	}
+	if err := flownet.SanityChecks.Circulation(c); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+
+	// an edge demand forces flow onto the expensive route.
+	c.AddEdge(0, 2, 10, 4)
+	if err := c.CostScaling(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 1 || c.Flow(0, 2) != 4 || c.Cost() != 1*1+4*4 {
+		t.Errorf("expected flows of 1 and 4 at cost 17, found %d and %d at cost %d", c.Flow(0, 1), c.Flow(0, 2), c.Cost())
+	}
+
+	c.SetNodeDemand(3, 50)
+	if err := c.CostScaling(); !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+}
+
+func TestCostScaling_NegativeCycles(t *testing.T) {
+	// with no demands, flow only circulates around cycles of negative cost.
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 3, 0)
+	c.AddEdge(1, 0, 4, 0)
+	c.AddEdge(1, 2, 5, 0)
+	c.AddEdge(2, 0, 5, 0)
+	c.SetEdgeCost(0, 1, -5)
+	c.SetEdgeCost(1, 0, 1)
+	c.SetEdgeCost(1, 2, 3)
+	c.SetEdgeCost(2, 0, 3)
+	if err := c.CostScaling(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 3 || c.Flow(1, 0) != 3 || c.Flow(1, 2) != 0 || c.Cost() != -12 {
+		t.Errorf("expected 3 units around the cycle 0 -> 1 -> 0 at cost -12, found cost %d", c.Cost())
+	}
+
+	c.AddEdge(0, 1, flownet.Infinite, 0)
+	c.AddEdge(1, 0, flownet.Infinite, 0)
+	if err := c.CostScaling(); !errors.Is(err, flownet.ErrNegativeCycle) {
+		t.Errorf("expected ErrNegativeCycle, found %v", err)
+	}
+	if err := c.SetEdgeCost(0, 3, 1); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
+
+func TestCostScaling_UnmetEdgeDemand(t *testing.T) {
+	// the demand of 1 -> 2 sends 5 units to node 2, which has no way to send them on. The supply of node 0
+	// must not make up for them.
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 10, 0)
+	c.AddEdge(1, 2, 5, 5)
+	c.SetNodeDemand(0, -10)
+	if err := c.CostScaling(); !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+	if c.SatisfiesDemand() {
+		t.Errorf("expected the demand not to be satisfied")
+	}
+
+	// once node 2 can return the flow to node 0, the 5 units circulate.
+	c.AddEdge(2, 0, 5, 0)
+	if err := c.CostScaling(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 5 || c.Flow(1, 2) != 5 || c.Flow(2, 0) != 5 {
+		t.Errorf("expected 5 units around the cycle, found %d, %d, and %d", c.Flow(0, 1), c.Flow(1, 2), c.Flow(2, 0))
+	}
+	if err := flownet.SanityChecks.Circulation(c); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+}
+
+func TestCostScaling_CostOverflow(t *testing.T) {
+	// each unit around the cycle is cheap enough to route, but the 100 units demanded cost more than an int64
+	// can hold.
+	c := flownet.NewCirculation(2)
+	c.AddEdge(0, 1, 100, 100)
+	c.AddEdge(1, 0, 100, 0)
+	c.SetEdgeCost(0, 1, math.MaxInt64/64)
+	solvers := map[string]func() error{
+		"CostScaling":    c.CostScaling,
+		"NetworkSimplex": c.NetworkSimplex,
+	}
+	for name, solve := range solvers {
+		if err := solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+			t.Errorf("%s: expected ErrCapacityOverflow, found %v", name, err)
+		}
+		if !c.SatisfiesDemand() || c.Cost() != 0 {
+			t.Errorf("%s: expected the demand to be met with no cost, found a flow of %d at cost %d", name, c.Flow(0, 1), c.Cost())
+		}
+	}
+}
+
+func TestCostScaling_Instances(t *testing.T) {
+	for _, suffix := range []string{CircInstances, FlowInstances} {
+		visitAllInstances(t, suffix, func(t *testing.T, path string, instance TestInstance) error {
+			c := instanceCirculation(instance)
+			edges := circulationEdges(instance)
+			err := c.CostScaling()
+			if !feasibleCirculation(&c, edges, instance.numNodes, nil) {
+				if !errors.Is(err, flownet.ErrInfeasible) {
+					t.Errorf("%s: expected ErrInfeasible, found %v", path, err)
+				}
+				return nil
+			}
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", path, err)
+			}
+			checkCheapestCirculation(t, path, &c, edges, instance.numNodes, nil)
+			if err := flownet.SanityChecks.Circulation(c); err != nil {
+				t.Errorf("%s: sanity checks failed: %v", path, err)
+			}
+			return nil
+		})
+	}
+}
+
+// circulationEdges returns the edges of the provided instance which instanceCirculation adds to a circulation.
+func circulationEdges(instance TestInstance) []Edge {
+	var result []Edge
+	for e, capacity := range instance.capacities {
+		if e.from >= 0 && e.to >= 0 && capacity > 0 {
+			result = append(result, e)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].from < result[j].from || (result[i].from == result[j].from && result[i].to < result[j].to)
+	})
+	return result
+}
+
+// feasibleCirculation reports whether any flow along the provided edges of c meets all of its edge and node
+// demands, while storing flow at the nodes of a transshipment within the provided bounds. Edge demands are removed in the usual way, by sending them from a new source to the head of each
+// edge and from its tail to a new sink, and a maximum flow between the two must use every edge they join.
+func feasibleCirculation(c *flownet.Circulation, edges []Edge, numNodes int, stored map[int]storage) bool {
+	// flow is supplied from one node and met at another, which passes it back to the first.
+	supplier, consumer, source, sink := numNodes, numNodes+1, numNodes+2, numNodes+3
+	capacities := map[Edge]int64{{consumer, supplier}: flownet.Infinite}
+	excess := make(map[int]int64)
+	require := func(from, to int, demand int64) {
+		excess[to] += demand
+		excess[from] -= demand
+	}
+	for _, e := range edges {
+		lower := c.EdgeDemand(e.from, e.to)
+		capacities[Edge{outHalf(c, e.from), e.to}] = c.Capacity(e.from, e.to) - lower
+		require(outHalf(c, e.from), e.to, lower)
+	}
+	for u := 0; u < numNodes; u++ {
+		if h := outHalf(c, u); h != u {
+			capacities[Edge{u, h}] = c.NodeCapacity(u)
+		}
+		if demand := c.NodeDemand(u); demand < 0 {
+			capacities[Edge{supplier, u}] = -demand
+		} else if demand > 0 {
+			require(outHalf(c, u), consumer, demand)
+		}
+	}
+	for u, s := range stored {
+		capacities[Edge{outHalf(c, u), consumer}] = s.max - s.min
+		require(outHalf(c, u), consumer, s.min)
+	}
+	required := int64(0)
+	for u, x := range excess {
+		if x > 0 {
+			capacities[Edge{source, u}] = x
+			required += x
+		} else if x < 0 {
+			capacities[Edge{u, sink}] = -x
+		}
+	}
+	flow, _ := maxFlow(capacities, source, sink)
+	return flow == required
+}
+
+// checkCheapestCirculation reports an error unless the flow along the provided edges of c, along with the flow
+// stored at the nodes of a transshipment, meets every edge and node demand, costs what c reports, and is
+// cheapest, so that no cycle of negative cost remains in its residual network. Nodes which supply flow may
+// leave some of their supply unused, so the residual network joins them through a node of its own, which
+// receives any flow which is stored.
+func checkCheapestCirculation(t *testing.T, path string, c *flownet.Circulation, edges []Edge, numNodes int, stored map[int]storage) {
+	t.Helper()
+	var arcs []costArc
+	net, inflow := make(map[int]int64), make(map[int]int64)
+	cost := int64(0)
+	for _, e := range edges {
+		flow, lower, upper := c.Flow(e.from, e.to), c.EdgeDemand(e.from, e.to), c.Capacity(e.from, e.to)
+		if flow < lower || flow > upper {
+			t.Errorf("%s: flow %d along edge %v is outside its bounds [%d, %d]", path, flow, e, lower, upper)
+		}
+		net[e.from] -= flow
+		net[e.to] += flow
+		inflow[e.to] += flow
+		cost += flow * c.EdgeCost(e.from, e.to)
+		arcs = residualArcs(arcs, outHalf(c, e.from), e.to, flow, lower, upper, c.EdgeCost(e.from, e.to))
+	}
+	supplier, consumer := numNodes, numNodes+1
+	arcs = append(arcs, costArc{consumer, supplier, 0})
+	for u, s := range stored {
+		if s.flow < s.min || s.flow > s.max {
+			t.Errorf("%s: %d units stored at node %d, outside its bounds [%d, %d]", path, s.flow, u, s.min, s.max)
+		}
+		net[u] -= s.flow
+		cost += s.flow * s.cost
+		arcs = residualArcs(arcs, outHalf(c, u), consumer, s.flow, s.min, s.max, s.cost)
+	}
+	for u := 0; u < numNodes; u++ {
+		demand := c.NodeDemand(u)
+		if demand < 0 {
+			arcs = residualArcs(arcs, supplier, u, -net[u], 0, -demand, 0)
+			inflow[u] -= net[u]
+			if net[u] < 0 {
+				arcs = append(arcs, costArc{supplier, consumer, 0})
+			}
+		}
+		if demand < 0 && (net[u] > 0 || net[u] < demand) || demand >= 0 && net[u] != demand {
+			t.Errorf("%s: node %d with demand %d receives %d", path, u, demand, net[u])
+		}
+		// flow supplied to a node with a node capacity passes through it, along with the flow it receives.
+		if h := outHalf(c, u); h != u {
+			if inflow[u] > c.NodeCapacity(u) {
+				t.Errorf("%s: %d units pass through node %d with capacity %d", path, inflow[u], u, c.NodeCapacity(u))
+			}
+			arcs = residualArcs(arcs, u, h, inflow[u], 0, c.NodeCapacity(u), 0)
+		}
+	}
+	if cost != c.Cost() {
+		t.Errorf("%s: expected cost %d, found %d", path, cost, c.Cost())
+	}
+	if hasNegativeCycle(arcs) {
+		t.Errorf("%s: the residual network has a cycle of negative cost, so the circulation is not cheapest", path)
+	}
+}
+
+// storage describes the flow stored at a node of a transshipment: its bounds, its cost per unit, and the
+// amount stored.
+type storage struct {
+	min, max, cost, flow int64
+}
+
+// outHalf returns the ID given by feasibleCirculation and checkCheapestCirculation to the half of a node which
+// flow leaves, which is a negative ID other than Source or Sink if the node has a node capacity.
+func outHalf(c *flownet.Circulation, u int) int {
+	if c.NodeCapacity(u) == flownet.Infinite {
+		return u
+	}
+	return -3 - u
+}
@@ -0,0 +1,211 @@
+package flownet
+
+import "sort"
+
+// A FlowPath is a path from the source to the sink, or a cycle, along which some amount of flow is sent.
+type FlowPath struct {
+	// Nodes contains the IDs of the nodes along the path, in order. Each pair of consecutive nodes is joined
+	// by an edge. Paths begin at Source and end at Sink. The last node of a cycle is the same as its first.
+	Nodes []int
+	// Flow is the amount of flow sent along every edge of the path.
+	Flow int64
+	// Cycle is true if the path is a cycle.
+	Cycle bool
+}
+
+// DecomposeFlow splits the flow found by the last solve into paths from the source to the sink and cycles.
+// Summing the flow of every path and cycle which uses an edge yields the flow along that edge, and the flow
+// of the paths sums to Outflow. Paths are found before cycles. The result is only meaningful once a flow
+// has been found; any flow which does not satisfy flow conservation is omitted.
+func (g FlowNetwork) DecomposeFlow() []FlowPath {
+	return g.mergeHidden(decomposeFlow(g.numInternalNodes(), g.preflow))
+}
+
+// DecomposeFlow splits the circulation found by the last solve into paths and cycles, as described for
+// FlowNetwork.DecomposeFlow. Flows are adjusted for edge demands, as for Flow. Each path begins at Source,
+// followed by a node which supplies flow, and ends at Sink, preceded by a node which consumes flow.
+func (c *Circulation) DecomposeFlow() []FlowPath {
+	return decomposeFlow(c.numNodes+2, c.nodeFlows(c.hiddenNodes()))
+}
+
+// mergeHidden replaces the hidden out-half of each node with a node capacity by the node itself, in each of
+// the provided paths.
+func (g FlowNetwork) mergeHidden(paths []FlowPath) []FlowPath {
+	if len(g.inHalf) == 0 {
+		return paths
+	}
+	for i, p := range paths {
+		nodes := p.Nodes[:0]
+		for _, u := range p.Nodes {
+			u = externalID(g.visibleNode(internalID(u)))
+			if len(nodes) == 0 || nodes[len(nodes)-1] != u {
+				nodes = append(nodes, u)
+			}
+		}
+		paths[i].Nodes = nodes
+	}
+	return paths
+}
+
+// hiddenNodes returns the set of nodes which were added to the network internally, by internal ID.
+func (c *Circulation) hiddenNodes() map[int]bool {
+	hidden := make(map[int]bool)
+	if c.nodeSource != 0 {
+		hidden[internalID(c.nodeSource)] = true
+		hidden[internalID(c.nodeSink)] = true
+	}
+	return hidden
+}
+
+// nodeFlows returns the demand-adjusted flow along every edge between two nodes which are not hidden, keyed
+// by internal IDs. Edges leaving the out-half of a node with a node capacity are keyed by the node itself,
+// and parallel edges are combined.
+// Each node which sends more flow than it receives receives the difference from the source, and each node
+// which receives more flow than it sends sends the difference to the sink.
+func (c *Circulation) nodeFlows(hidden map[int]bool) map[edge]int64 {
+	flows := make(map[edge]int64)
+	balance := make([]int64, c.numNodes+2)
+	seen := make(map[edge]bool)
+	for e := range c.FlowNetwork.capacity {
+		e, ok := c.visibleEdge(e)
+		if !ok || seen[e] || e.from < 2 || e.to < 2 || hidden[e.from] || hidden[e.to] {
+			continue
+		}
+		seen[e] = true
+		if flow := c.Flow(externalID(e.from), externalID(e.to)); flow > 0 {
+			flows[e] = flow
+			balance[e.from] -= flow
+			balance[e.to] += flow
+		}
+	}
+	for u := 2; u < c.numNodes+2; u++ {
+		if balance[u] < 0 {
+			flows[edge{sourceID, u}] = -balance[u]
+		}
+		if balance[u] > 0 {
+			flows[edge{u, sinkID}] = balance[u]
+		}
+	}
+	return flows
+}
+
+// flowArc is an edge carrying flow, stored in the adjacency list of its tail.
+type flowArc struct {
+	to   int
+	flow int64
+}
+
+// decomposeFlow splits the provided flows, keyed by internal IDs, into paths and cycles.
+func decomposeFlow(numNodes int, flows map[edge]int64) []FlowPath {
+	d := flowDecomposition{
+		out:      make([][]flowArc, numNodes),
+		current:  make([]int, numNodes),
+		position: make([]int, numNodes),
+	}
+	for e, flow := range flows {
+		if flow > 0 {
+			d.out[e.from] = append(d.out[e.from], flowArc{e.to, flow})
+		}
+	}
+	for u := range d.out {
+		arcs := d.out[u]
+		sort.Slice(arcs, func(i, j int) bool { return arcs[i].to < arcs[j].to })
+		d.position[u] = -1
+	}
+	for d.walk(sourceID) {
+	}
+	for u := 2; u < numNodes; u++ {
+		for d.walk(u) {
+		}
+	}
+	return d.paths
+}
+
+// flowDecomposition stores the state of a flow decomposition.
+type flowDecomposition struct {
+	// out stores the arcs leaving each node, along with the flow not yet assigned to a path.
+	out [][]flowArc
+	// current stores the index of the first arc leaving each node which may still carry flow.
+	current []int
+	// position stores the position of each node in the current walk, or -1 if the node is not in it.
+	position []int
+	// paths stores the paths and cycles found so far.
+	paths []FlowPath
+}
+
+// walk follows flow from start until reaching the sink or getting stuck, splitting off any cycles found
+// along the way. Returns false if no flow leaves start.
+func (d *flowDecomposition) walk(start int) bool {
+	// arcs[i] is the index of the arc from walk[i] to walk[i+1].
+	walk, arcs := []int{start}, []int{}
+	d.position[start] = 0
+	defer func() {
+		for _, u := range walk {
+			d.position[u] = -1
+		}
+	}()
+	for {
+		u := walk[len(walk)-1]
+		if u == sinkID {
+			flow := d.subtract(walk, arcs)
+			if start == sourceID {
+				d.paths = append(d.paths, FlowPath{Nodes: externalIDs(walk), Flow: flow})
+			}
+			return true
+		}
+		arc := d.nextArc(u)
+		if arc < 0 {
+			// flow is not conserved at u; discard the flow along the walk so far.
+			d.subtract(walk, arcs)
+			return len(arcs) > 0
+		}
+		v := d.out[u][arc].to
+		if i := d.position[v]; i >= 0 {
+			cycle := append(append([]int{}, walk[i:]...), v)
+			flow := d.subtract(cycle, append(append([]int{}, arcs[i:]...), arc))
+			d.paths = append(d.paths, FlowPath{Nodes: externalIDs(cycle), Flow: flow, Cycle: true})
+			for _, w := range walk[i+1:] {
+				d.position[w] = -1
+			}
+			walk, arcs = walk[:i+1], arcs[:i]
+			continue
+		}
+		d.position[v] = len(walk)
+		walk, arcs = append(walk, v), append(arcs, arc)
+	}
+}
+
+// nextArc returns the index of the next arc leaving u which carries flow, or -1 if there are none.
+func (d *flowDecomposition) nextArc(u int) int {
+	for ; d.current[u] < len(d.out[u]); d.current[u]++ {
+		if d.out[u][d.current[u]].flow > 0 {
+			return d.current[u]
+		}
+	}
+	return -1
+}
+
+// subtract removes the smallest flow along the provided arcs from each of them, and returns it. arcs[i] must
+// be the index of an arc leaving nodes[i].
+func (d *flowDecomposition) subtract(nodes []int, arcs []int) int64 {
+	if len(arcs) == 0 {
+		return 0
+	}
+	flow := d.out[nodes[0]][arcs[0]].flow
+	for i, arc := range arcs {
+		flow = min64(flow, d.out[nodes[i]][arc].flow)
+	}
+	for i, arc := range arcs {
+		d.out[nodes[i]][arc].flow -= flow
+	}
+	return flow
+}
+
+// externalIDs maps each of the provided internal IDs to an external ID.
+func externalIDs(nodeIDs []int) []int {
+	result := make([]int, len(nodeIDs))
+	for i, u := range nodeIDs {
+		result[i] = externalID(u)
+	}
+	return result
+}
@@ -0,0 +1,152 @@
+package flownet_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestDecomposeFlowAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			graph.AddEdge(edge.from, edge.to, cap)
+		}
+		graph.PushRelabel()
+		paths := graph.DecomposeFlow()
+		if err := checkDecomposition(paths, graph.Flow); err != nil {
+			t.Errorf("failed test %s: %v", path, err)
+			return err
+		}
+		for edge := range instance.capacities {
+			if graph.Flow(edge.from, edge.to) != pathFlow(paths, edge.from, edge.to) {
+				t.Errorf("failed test %s: flow along edge from %d to %d is missing from the decomposition", path, edge.from, edge.to)
+			}
+		}
+		total := int64(0)
+		for _, p := range paths {
+			if !p.Cycle {
+				total += p.Flow
+			}
+		}
+		if total != graph.Outflow() {
+			t.Errorf("failed test %s: paths carry %d units of flow, but outflow is %d", path, total, graph.Outflow())
+		}
+		return nil
+	})
+}
+
+func TestDecomposeFlowAllCirculations(t *testing.T) {
+	visitAllInstances(t, CircInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewCirculation(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if edge.from == flownet.Source {
+				graph.SetNodeDemand(edge.to, -10)
+			}
+			if edge.to == flownet.Sink {
+				graph.SetNodeDemand(edge.from, 10)
+			}
+			if edge.from < 0 || edge.to < 0 || cap <= 0 {
+				continue
+			}
+			graph.AddEdge(edge.from, edge.to, cap, instance.demands[edge])
+		}
+		graph.PushRelabel()
+		if !graph.SatisfiesDemand() {
+			return nil
+		}
+		paths := graph.DecomposeFlow()
+		for _, p := range paths {
+			for _, u := range p.Nodes {
+				if u >= instance.numNodes {
+					t.Errorf("failed test %s: decomposition visits hidden node %d", path, u)
+					return nil
+				}
+			}
+		}
+		for edge := range instance.capacities {
+			if edge.from < 0 || edge.to < 0 {
+				continue
+			}
+			if graph.Flow(edge.from, edge.to) != pathFlow(paths, edge.from, edge.to) {
+				t.Errorf("failed test %s: flow of %d along edge from %d to %d does not match the decomposition", path,
+					graph.Flow(edge.from, edge.to), edge.from, edge.to)
+			}
+		}
+		return nil
+	})
+}
+
+func TestDecomposeFlow(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(flownet.Source, 0, 5)
+	graph.AddEdge(0, 1, 3)
+	graph.AddEdge(0, 2, 2)
+	graph.AddEdge(1, flownet.Sink, 3)
+	graph.AddEdge(2, flownet.Sink, 2)
+	graph.Dinic()
+
+	expected := []flownet.FlowPath{
+		{Nodes: []int{flownet.Source, 0, 1, flownet.Sink}, Flow: 3},
+		{Nodes: []int{flownet.Source, 0, 2, flownet.Sink}, Flow: 2},
+	}
+	if paths := graph.DecomposeFlow(); !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected paths %v, found %v", expected, paths)
+	}
+}
+
+func TestDecomposeFlow_Cycle(t *testing.T) {
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 5, 4)
+	c.AddEdge(1, 2, 5, 0)
+	c.AddEdge(2, 0, 5, 0)
+	c.PushRelabel()
+	if !c.SatisfiesDemand() {
+		t.Fatalf("expected demand to be satisfied")
+	}
+	paths := c.DecomposeFlow()
+	if len(paths) != 1 || !paths[0].Cycle {
+		t.Fatalf("expected a single cycle, found %v", paths)
+	}
+	if err := checkDecomposition(paths, c.Flow); err != nil {
+		t.Error(err)
+	}
+}
+
+// pathFlow returns the total flow of the paths which use the provided edge.
+func pathFlow(paths []flownet.FlowPath, from, to int) int64 {
+	result := int64(0)
+	for _, p := range paths {
+		for i := 0; i+1 < len(p.Nodes); i++ {
+			if p.Nodes[i] == from && p.Nodes[i+1] == to {
+				result += p.Flow
+			}
+		}
+	}
+	return result
+}
+
+// checkDecomposition returns an error if any path or cycle is malformed, or if the paths use an edge
+// more than the flow along it allows.
+func checkDecomposition(paths []flownet.FlowPath, flow func(from, to int) int64) error {
+	for _, p := range paths {
+		if p.Flow <= 0 {
+			return fmt.Errorf("path %v has non-positive flow %d", p.Nodes, p.Flow)
+		}
+		first, last := p.Nodes[0], p.Nodes[len(p.Nodes)-1]
+		if p.Cycle && first != last {
+			return fmt.Errorf("cycle %v does not end where it began", p.Nodes)
+		}
+		if !p.Cycle && (first != flownet.Source || last != flownet.Sink) {
+			return fmt.Errorf("path %v does not lead from the source to the sink", p.Nodes)
+		}
+		for i := 0; i+1 < len(p.Nodes); i++ {
+			if from, to := p.Nodes[i], p.Nodes[i+1]; pathFlow(paths, from, to) != flow(from, to) {
+				return fmt.Errorf("paths send %d units of flow from %d to %d, but the flow is %d", pathFlow(paths, from, to), from, to, flow(from, to))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package flownet
+
+import "math"
+
+// Dinic finds a maximum flow via Dinic's algorithm. Each phase of the algorithm builds a level graph
+// using a breadth-first search of the residual network from the source, and then saturates the level
+// graph with a blocking flow found by repeated depth-first searches. Dinic's algorithm is often much
+// faster than PushRelabel on sparse networks whose capacities are small.
+//
+// After Dinic has been called, Outflow, Flow, and Residual report the maximum flow found, just as they
+// would after calling PushRelabel. An error wrapping ErrCapacityOverflow or ErrUnboundedFlow is returned
+// as by Solve, in which case no flow is found.
+func (g *FlowNetwork) Dinic() error {
+	g.clearFlow()
+	if err := g.prepare(); err != nil {
+		return err
+	}
+	g.dinic()
+	return nil
+}
+
+// dinic augments the current flow until it is maximum.
+func (g *FlowNetwork) dinic() {
+	a := g.compile()
+	a.dinic()
+	g.writeBack(a)
+}
+
+// dinic augments the flow in the arcGraph until it is maximum.
+func (a *arcGraph) dinic() {
+	level := make([]int32, a.numNodes())
+	next := make([]int, a.numNodes())
+	for a.levelGraph(level) {
+		copy(next, a.first)
+		for a.blockingPath(sourceID, math.MaxInt64, level, next) > 0 {
+		}
+	}
+}
+
+// levelGraph stores the BFS distance from the source to each node in the residual network into level;
+// unreachable nodes receive a level of -1. Returns true iff the sink is reachable from the source.
+func (a *arcGraph) levelGraph(level []int32) bool {
+	for u := range level {
+		level[u] = -1
+	}
+	level[sourceID] = 0
+	frontier := []int32{sourceID}
+	for len(frontier) > 0 {
+		u := frontier[0]
+		frontier = frontier[1:]
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			v := a.head[arc]
+			if level[v] < 0 && a.residual[arc] > 0 {
+				level[v] = level[u] + 1
+				frontier = append(frontier, v)
+			}
+		}
+	}
+	return level[sinkID] >= 0
+}
+
+// blockingPath finds a path from nodeID to the sink in the level graph which can carry at most limit units
+// of flow and augments the flow along it, returning the amount of flow added. Arcs which cannot be used
+// are skipped in future calls by advancing next.
+func (a *arcGraph) blockingPath(nodeID int, limit int64, level []int32, next []int) int64 {
+	if nodeID == sinkID {
+		return limit
+	}
+	for ; next[nodeID] < a.first[nodeID+1]; next[nodeID]++ {
+		arc := next[nodeID]
+		v := int(a.head[arc])
+		if level[v] != level[nodeID]+1 || a.residual[arc] <= 0 {
+			continue
+		}
+		if delta := a.blockingPath(v, min64(limit, a.residual[arc]), level, next); delta > 0 {
+			a.push(arc, delta)
+			return delta
+		}
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package flownet_test
+
+import (
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestDinicAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		dinic := flownet.NewFlowNetwork(instance.numNodes)
+		pushRelabel := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if _, err := dinic.AddEdge(edge.from, edge.to, cap); err != nil {
+				t.Error(err)
+			}
+			pushRelabel.AddEdge(edge.from, edge.to, cap)
+		}
+		dinic.Dinic()
+		pushRelabel.PushRelabel()
+		outflow := dinic.Outflow()
+		if outflow != pushRelabel.Outflow() {
+			t.Errorf("failed test %s: Dinic found max flow of %d but PushRelabel found %d", path, outflow, pushRelabel.Outflow())
+		}
+		if instance.expectedFlow != -1 && instance.expectedFlow != outflow {
+			t.Errorf("failed test %s expected max-flow of %d but was %d", path, instance.expectedFlow, outflow)
+		}
+		if err := flownet.SanityChecks.FlowNetwork(dinic, true); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestDinicAllCirculations(t *testing.T) {
+	visitAllInstances(t, CircInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewCirculation(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if edge.from == flownet.Source {
+				graph.SetNodeDemand(edge.to, -10)
+			}
+			if edge.to == flownet.Sink {
+				graph.SetNodeDemand(edge.from, 10)
+			}
+			if edge.from < 0 || edge.to < 0 || cap <= 0 {
+				continue
+			}
+			if err := graph.AddEdge(edge.from, edge.to, cap, instance.demands[edge]); err != nil {
+				t.Error(err)
+			}
+		}
+		graph.Dinic()
+		if err := flownet.SanityChecks.Circulation(graph); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestDinic_ManualSource(t *testing.T) {
+	for _, solve := range []func(*flownet.FlowNetwork) error{(*flownet.FlowNetwork).Dinic, (*flownet.FlowNetwork).Solve} {
+		g := flownet.NewFlowNetwork(3)
+		g.AddEdge(flownet.Source, 0, 3)
+		g.AddEdge(0, 1, 10)
+		g.AddEdge(1, 2, 10)
+		if err := solve(&g); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if g.Outflow() != 3 {
+			t.Errorf("expected a max flow of 3 limited by the source edge, found %d", g.Outflow())
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package flownet
+
+import "fmt"
+
+// EdgeDisjointPaths returns a largest set of paths from the node with ID from to the node with ID to in
+// the provided FlowNetwork, such that no two paths share an edge. Every edge with positive capacity between
+// two nodes is used, whatever its capacity, and parallel edges count separately; edges to or from the source
+// and sink are ignored. Each path is
+// listed as the IDs of the nodes along it, beginning with from and ending with to. An error is returned if
+// from and to are the same node, or if either is not a valid node ID.
+func EdgeDisjointPaths(fn FlowNetwork, from, to int) ([][]int, error) {
+	if err := checkTerminals(fn, from, to); err != nil {
+		return nil, err
+	}
+	return disjointPaths(fn, from, to, false), nil
+}
+
+// VertexDisjointPaths returns a largest set of paths from the node with ID from to the node with ID to in
+// the provided FlowNetwork, such that no two paths share any node other than from and to. Edges are used as
+// described for EdgeDisjointPaths. An error is returned if from and to are the same node, or if either is
+// not a valid node ID.
+func VertexDisjointPaths(fn FlowNetwork, from, to int) ([][]int, error) {
+	if err := checkTerminals(fn, from, to); err != nil {
+		return nil, err
+	}
+	return disjointPaths(fn, from, to, true), nil
+}
+
+// EdgeConnectivity returns the smallest number of edges which must be removed from the provided FlowNetwork
+// so that some node can no longer reach some other node. Edges are used as described for EdgeDisjointPaths.
+// Networks with fewer than two nodes have an edge connectivity of zero.
+func EdgeConnectivity(fn FlowNetwork) int {
+	nodes := fn.visibleNodes()
+	if len(nodes) < 2 {
+		return 0
+	}
+	// any cut separates the first node from some node v, in one direction or the other.
+	first := nodes[0]
+	result := disjointPathCount(fn, first, nodes[1], false)
+	for i, v := range nodes[1:] {
+		if i > 0 {
+			result = min(result, disjointPathCount(fn, first, v, false))
+		}
+		result = min(result, disjointPathCount(fn, v, first, false))
+	}
+	return result
+}
+
+// VertexConnectivity returns the smallest number of nodes which must be removed from the provided
+// FlowNetwork so that some remaining node can no longer reach some other remaining node, or one less than
+// the number of nodes if every node has an edge to every other node. Edges are used as described for
+// EdgeDisjointPaths.
+func VertexConnectivity(fn FlowNetwork) int {
+	nodes := fn.visibleNodes()
+	if len(nodes) < 2 {
+		return 0
+	}
+	// some node among the first k+1 lies outside any separator of size k, and is separated from some node
+	// which comes after it (Even's algorithm). No separator exists between adjacent nodes.
+	result := len(nodes) - 1
+	for i := 0; i <= result && i < len(nodes); i++ {
+		for _, v := range nodes[i+1:] {
+			u := nodes[i]
+			if fn.Capacity(u, v) <= 0 {
+				result = min(result, disjointPathCount(fn, u, v, true))
+			}
+			if fn.Capacity(v, u) <= 0 {
+				result = min(result, disjointPathCount(fn, v, u, true))
+			}
+		}
+	}
+	return result
+}
+
+// checkTerminals returns an error if from and to are the same node, or if either is not a valid node ID.
+func checkTerminals(fn FlowNetwork, from, to int) error {
+	if from < 0 || from >= fn.numNodes {
+		return fmt.Errorf("no node with ID %d is known", from)
+	}
+	if to < 0 || to >= fn.numNodes {
+		return fmt.Errorf("no node with ID %d is known", to)
+	}
+	if from == to {
+		return fmt.Errorf("paths must join two different nodes, found %d twice", from)
+	}
+	return nil
+}
+
+// disjointPathCount returns the number of paths found by disjointPaths.
+func disjointPathCount(fn FlowNetwork, from, to int, vertexDisjoint bool) int {
+	unit := unitNetwork(fn, from, to, vertexDisjoint)
+	// only the edges joined to the source and sink have Infinite capacity, and the rest are small, so Dinic
+	// cannot fail.
+	_ = unit.Dinic()
+	return int(unit.Outflow())
+}
+
+// disjointPaths returns a largest set of edge-disjoint paths from one node to another, or of vertex-disjoint
+// paths if vertexDisjoint is true.
+func disjointPaths(fn FlowNetwork, from, to int, vertexDisjoint bool) [][]int {
+	unit := unitNetwork(fn, from, to, vertexDisjoint)
+	_ = unit.Dinic()
+	var result [][]int
+	for _, p := range unit.DecomposeFlow() {
+		if p.Cycle {
+			continue
+		}
+		// drop the source and sink, and map both halves of each split node back to the node itself.
+		path := make([]int, 0, len(p.Nodes)-2)
+		for _, u := range p.Nodes[1 : len(p.Nodes)-1] {
+			u %= fn.numNodes
+			if len(path) == 0 || path[len(path)-1] != u {
+				path = append(path, u)
+			}
+		}
+		for i := int64(0); i < p.Flow; i++ {
+			result = append(result, path)
+		}
+	}
+	return result
+}
+
+// unitNetwork returns a FlowNetwork in which every edge between two nodes of fn has unit capacity, with
+// flow entering at from and leaving at to. If vertexDisjoint is true, each node v is split into an
+// incoming half with ID v and an outgoing half with ID v + fn.numNodes, joined by an edge of unit capacity.
+// Node capacities in fn are ignored.
+func unitNetwork(fn FlowNetwork, from, to int, vertexDisjoint bool) FlowNetwork {
+	n := fn.numNodes
+	outHalf := func(u int) int { return u }
+	if vertexDisjoint {
+		outHalf = func(u int) int { return u + fn.numNodes }
+		n *= 2
+	}
+	result := NewFlowNetwork(n)
+	result.AddEdge(Source, outHalf(from), Infinite)
+	result.AddEdge(to, Sink, Infinite)
+	if vertexDisjoint {
+		for u := 0; u < fn.numNodes; u++ {
+			if u != from && u != to {
+				result.AddEdge(u, outHalf(u), 1)
+			}
+		}
+	}
+	for e := range fn.capacity {
+		// each parallel edge with positive capacity adds a unit of capacity.
+		visible, ok := fn.visibleEdge(e)
+		if count := fn.multiplicity(e); ok && count > 0 && visible.from >= 2 && visible.to >= 2 {
+			result.AddEdge(outHalf(externalID(visible.from)), externalID(visible.to), int64(count))
+		}
+	}
+	return result
+}
@@ -0,0 +1,204 @@
+package flownet_test
+
+import (
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestEdgeDisjointPaths(t *testing.T) {
+	// two routes from 0 to 3 share the edge 1 -> 2, so only two edge-disjoint paths exist.
+	graph := flownet.NewFlowNetwork(5)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(0, 4, 5)
+	graph.AddEdge(4, 1, 5)
+	graph.AddEdge(1, 2, 5)
+	graph.AddEdge(2, 3, 5)
+	graph.AddEdge(4, 3, 5)
+	paths, err := flownet.EdgeDisjointPaths(graph, 0, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, found %v", paths)
+	}
+	checkDisjointPaths(t, graph, paths, 0, 3, false)
+}
+
+func TestVertexDisjointPaths(t *testing.T) {
+	// both routes from 0 to 3 pass through node 1, so only one vertex-disjoint path exists.
+	graph := flownet.NewFlowNetwork(5)
+	graph.AddEdge(0, 1, 1)
+	graph.AddEdge(0, 2, 1)
+	graph.AddEdge(2, 1, 1)
+	graph.AddEdge(1, 4, 1)
+	graph.AddEdge(1, 3, 1)
+	graph.AddEdge(4, 3, 1)
+	edgePaths, _ := flownet.EdgeDisjointPaths(graph, 0, 3)
+	if len(edgePaths) != 2 {
+		t.Errorf("expected 2 edge-disjoint paths, found %v", edgePaths)
+	}
+	paths, err := flownet.VertexDisjointPaths(graph, 0, 3)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, found %v", paths)
+	}
+	checkDisjointPaths(t, graph, paths, 0, 3, true)
+}
+
+func TestDisjointPaths_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph, units := unitInstance(instance)
+		from, to := 0, instance.numNodes-1
+		for _, vertexDisjoint := range []bool{false, true} {
+			find, capacities := flownet.EdgeDisjointPaths, units
+			if vertexDisjoint {
+				find, capacities = flownet.VertexDisjointPaths, splitNodes(units, instance.numNodes, 1, from, to)
+			}
+			paths, err := find(graph, from, to)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", path, err)
+			}
+			if expected, _ := maxFlow(capacities, from, to); int64(len(paths)) != expected {
+				t.Errorf("%s: expected %d disjoint paths when vertexDisjoint is %t, found %d", path, expected, vertexDisjoint, len(paths))
+			}
+			checkDisjointPaths(t, graph, paths, from, to, vertexDisjoint)
+		}
+		return nil
+	})
+}
+
+func TestConnectivity_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		n := instance.numNodes
+		if n > 30 {
+			return nil
+		}
+		// flow in the fixtures runs one way, so every connectivity would be zero unless edges run both ways.
+		_, directed := unitInstance(instance)
+		graph := flownet.NewFlowNetwork(n)
+		units := make(map[Edge]int64)
+		for e := range directed {
+			graph.AddEdge(e.from, e.to, 1)
+			graph.AddEdge(e.to, e.from, 1)
+			units[e], units[Edge{e.to, e.from}] = 1, 1
+		}
+		// check every ordered pair of nodes, rather than the pairs chosen by the package.
+		edgeConnectivity, vertexConnectivity := int64(n-1), int64(n-1)
+		for u := 0; u < n; u++ {
+			for v := 0; v < n; v++ {
+				if u == v {
+					continue
+				}
+				if k, _ := maxFlow(units, u, v); k < edgeConnectivity {
+					edgeConnectivity = k
+				}
+				if units[Edge{u, v}] > 0 {
+					continue
+				}
+				if k, _ := maxFlow(splitNodes(units, n, 1, u, v), u, v); k < vertexConnectivity {
+					vertexConnectivity = k
+				}
+			}
+		}
+		if k := flownet.EdgeConnectivity(graph); int64(k) != edgeConnectivity {
+			t.Errorf("%s: expected edge connectivity of %d, found %d", path, edgeConnectivity, k)
+		}
+		if k := flownet.VertexConnectivity(graph); int64(k) != vertexConnectivity {
+			t.Errorf("%s: expected vertex connectivity of %d, found %d", path, vertexConnectivity, k)
+		}
+		return nil
+	})
+}
+
+func TestDisjointPaths_Errors(t *testing.T) {
+	graph := flownet.NewFlowNetwork(2)
+	if _, err := flownet.EdgeDisjointPaths(graph, 0, 0); err == nil {
+		t.Errorf("expected an error when from and to are the same")
+	}
+	if _, err := flownet.VertexDisjointPaths(graph, 0, 2); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
+
+func TestConnectivity(t *testing.T) {
+	// a directed cycle on 4 nodes, with chords making it 2-edge-connected but only 1-vertex-connected.
+	graph := flownet.NewFlowNetwork(5)
+	for _, e := range [][2]int{{0, 1}, {1, 2}, {2, 0}, {1, 0}, {2, 1}, {0, 2}, {2, 3}, {3, 4}, {4, 2}, {3, 2}, {4, 3}, {2, 4}} {
+		graph.AddEdge(e[0], e[1], 1)
+	}
+	if k := flownet.EdgeConnectivity(graph); k != 2 {
+		t.Errorf("expected edge connectivity of 2, found %d", k)
+	}
+	if k := flownet.VertexConnectivity(graph); k != 1 {
+		t.Errorf("expected vertex connectivity of 1, found %d", k)
+	}
+
+	complete := flownet.NewFlowNetwork(4)
+	for u := 0; u < 4; u++ {
+		for v := 0; v < 4; v++ {
+			if u != v {
+				complete.AddEdge(u, v, 1)
+			}
+		}
+	}
+	if k := flownet.VertexConnectivity(complete); k != 3 {
+		t.Errorf("expected vertex connectivity of 3 for a complete graph, found %d", k)
+	}
+	if k := flownet.EdgeConnectivity(complete); k != 3 {
+		t.Errorf("expected edge connectivity of 3 for a complete graph, found %d", k)
+	}
+
+	path := flownet.NewFlowNetwork(3)
+	path.AddEdge(0, 1, 1)
+	path.AddEdge(1, 2, 1)
+	if k := flownet.EdgeConnectivity(path); k != 0 {
+		t.Errorf("expected edge connectivity of 0 for a directed path, found %d", k)
+	}
+}
+
+// checkDisjointPaths reports an error if any of the provided paths is not a path from one node to another
+// in the graph, or if two paths share an edge, or a node other than their endpoints when vertexDisjoint
+// is true.
+func checkDisjointPaths(t *testing.T, graph flownet.FlowNetwork, paths [][]int, from, to int, vertexDisjoint bool) {
+	t.Helper()
+	usedEdges := make(map[[2]int]bool)
+	usedNodes := make(map[int]bool)
+	for _, path := range paths {
+		if path[0] != from || path[len(path)-1] != to {
+			t.Errorf("path %v does not lead from %d to %d", path, from, to)
+		}
+		for i := 0; i+1 < len(path); i++ {
+			e := [2]int{path[i], path[i+1]}
+			if graph.Capacity(e[0], e[1]) <= 0 {
+				t.Errorf("path %v uses edge from %d to %d, which is not in the graph", path, e[0], e[1])
+			}
+			if usedEdges[e] {
+				t.Errorf("edge from %d to %d is used by more than one path", e[0], e[1])
+			}
+			usedEdges[e] = true
+		}
+		for _, u := range path[1 : len(path)-1] {
+			if vertexDisjoint && usedNodes[u] {
+				t.Errorf("node %d is used by more than one path", u)
+			}
+			usedNodes[u] = true
+		}
+	}
+}
+
+// unitInstance returns a FlowNetwork with the edges of the provided instance which join two of its nodes, along
+// with the same edges, each with unit capacity.
+func unitInstance(instance TestInstance) (flownet.FlowNetwork, map[Edge]int64) {
+	graph := flownet.NewFlowNetwork(instance.numNodes)
+	units := make(map[Edge]int64)
+	for e, c := range instance.capacities {
+		if e.from >= 0 && e.to >= 0 && e.from != e.to && c > 0 {
+			graph.AddEdge(e.from, e.to, c)
+			units[e] = 1
+		}
+	}
+	return graph, units
+}
@@ -0,0 +1,3 @@
+// Package flownet provides algorithms for solving maximum-flow, minimum-cut, and minimum-cost flow problems.
+// It also provides wrappers for specifying circulation, transshipment, and transportation problems.
+package flownet
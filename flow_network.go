@@ -2,9 +2,10 @@ package flownet
 
 import (
 	"container/heap"
+	"context"
 	"fmt"
-	"log"
 	"math"
+	"sort"
 )
 
 // Source is the ID of the source pseudonode.
@@ -19,12 +20,13 @@ const Sink int = -1
 // Each node may optionally be connected to a source or a sink node.
 // By default, nodes which do not have any incoming edges are presumed to be connected to the source,
 // while nodes which have no outgoing edges are presumed to be connected to the sink. These default
-// source/sink connections all have maximum capacity of math.MaxInt64. The first time AddEdge is called
+// source/sink connections all have Infinite capacity. The first time AddEdge is called
 // with a value of either flownet.Source or flownet.Sink, all the presumptive edges to the respective
 // node are cleared and the programmer becomes responsible for managing all edges to the Source or Sink,
 // respectively.
 type FlowNetwork struct {
-	// numNodes is the total number of nodes in this network other than the source and sink.
+	// numNodes is the total number of nodes in this network other than the source, the sink, and hidden
+	// nodes.
 	numNodes int
 	// nodeOrder contains the order in which nodes are discharged.
 	nodeOrder []int
@@ -40,12 +42,30 @@ type FlowNetwork struct {
 	excess []int64
 	// label stores the label of each node.
 	label []int
-	// seen stores the last node seen by each node for use during the discharge operation.
+	// seen stores the number of arcs leaving each node which have been seen during the discharge operation.
 	seen []int
 	// manualSource is true only if the programmer has manually added an edge leaving flownet.Source.
 	manualSource bool
 	// manualSink is true only if the programmer has manually added an edge entering flownet.Sink.
 	manualSink bool
+	// searchTrees stores the search trees left by the last run of BoykovKolmogorov, if any.
+	searchTrees *searchTrees
+	// infinity is the finite capacity used in place of Infinite while solving.
+	infinity int64
+	// outHalf maps each node which has a node capacity to its hidden out-half, by internal ID.
+	outHalf map[int]int
+	// inHalf maps each hidden out-half to the node it belongs to, by internal ID.
+	inHalf map[int]int
+	// undirected contains each undirected edge, keyed using external IDs with from < to.
+	undirected map[edge]struct{}
+	// edgeIDs stores the pair of nodes joined by each edge added via AddEdge or AddParallelEdge, indexed by
+	// EdgeID.
+	edgeIDs []edge
+	// edgeCapacity stores the capacity of each edge added via AddEdge or AddParallelEdge, indexed by EdgeID.
+	// The capacity between a pair of nodes is the total capacity of the edges joining them.
+	edgeCapacity []int64
+	// parallel maps each pair of nodes to the IDs of the edges joining them, in the order they were added.
+	parallel map[edge][]EdgeID
 }
 
 // Edge represents a directed edge from the node with ID 'from' to the node with ID 'to'.
@@ -89,6 +109,12 @@ func (e edge) reverse() edge {
 	return edge{from: e.to, to: e.from}
 }
 
+// numInternalNodes returns the number of internal node IDs in use: those of the source and sink, of every
+// node, and of every hidden node, which come last.
+func (g FlowNetwork) numInternalNodes() int {
+	return len(g.adjacencyList)
+}
+
 // sourceID is the internal ID for the source node.
 const sourceID = 0
 
@@ -112,8 +138,8 @@ func NewFlowNetwork(numNodes int) FlowNetwork {
 	for i := 0; i < numNodes; i++ {
 		result.adjacencyList[internalID(i)] = make(map[int]struct{})
 
-		result.addEdge(Source, i, math.MaxInt64)
-		result.addEdge(i, Sink, math.MaxInt64)
+		result.addEdge(Source, i, Infinite)
+		result.addEdge(i, Sink, Infinite)
 	}
 	return result
 }
@@ -130,27 +156,36 @@ func (g FlowNetwork) Outflow() int64 {
 	return result
 }
 
-// Flow returns the flow along an edge. Before PushRelabel is called this method returns 0.
+// Flow returns the total flow along the edges from one node to another. Before PushRelabel is called this
+// method returns 0. The flow along an undirected edge is the net flow from 'from' to 'to', which is negative
+// if flow runs the other way.
 func (g FlowNetwork) Flow(from, to int) int64 {
-	return g.preflow[newEdge(from, to)]
+	flow := g.preflow[newEdge(g.outHalfID(from), to)]
+	if _, ok := g.undirected[undirectedEdge(from, to)]; ok {
+		flow -= g.preflow[newEdge(g.outHalfID(to), from)]
+	}
+	return flow
 }
 
-// Residual returns the residual flow along an edge, defined as capacity - flow.
+// Residual returns the total residual flow along the edges from one node to another, defined as capacity -
+// flow. The residual flow along an edge with Infinite capacity is Infinite.
 func (g FlowNetwork) Residual(from, to int) int64 {
-	return g.residual(newEdge(from, to))
+	if g.Capacity(from, to) == Infinite {
+		return Infinite
+	}
+	return g.residual(newEdge(g.outHalfID(from), to))
 }
 
-// Capacity returns the capacity of the provided edge.
+// Capacity returns the total capacity of the edges from one node to another.
 func (g FlowNetwork) Capacity(from, to int) int64 {
-	return g.capacity[newEdge(from, to)]
+	return g.capacity[newEdge(g.outHalfID(from), to)]
 }
 
-// residual returns the same result as Residual, but could be cheaper for internal use.
+// residual returns the same result as Residual, but could be cheaper for internal use. Any flow along
+// the reverse of e can be cancelled, so it counts toward the residual. Infinite capacities are replaced by
+// their finite stand-in.
 func (g FlowNetwork) residual(e edge) int64 {
-	if g.capacity[e] == 0 {
-		return g.preflow[e.reverse()]
-	}
-	return g.capacity[e] - g.preflow[e]
+	return g.effectiveCapacity(e) - g.preflow[e] + g.preflow[e.reverse()]
 }
 
 // AddNode adds a new node to the graph and returns its ID, which must be used in subsequent
@@ -158,23 +193,80 @@ func (g FlowNetwork) residual(e edge) int64 {
 func (g *FlowNetwork) AddNode() int {
 	id := g.numNodes
 	g.numNodes++
-	g.excess = append(g.excess, 0)
-	g.label = append(g.label, 0)
-	g.seen = append(g.seen, 0)
-	g.adjacencyList = append(g.adjacencyList, make(map[int]struct{}))
+	g.insertNode(internalID(id))
 	if !g.manualSource {
-		g.addEdge(Source, id, math.MaxInt64)
+		g.addEdge(Source, id, Infinite)
 	}
 	if !g.manualSink {
-		g.addEdge(id, Sink, math.MaxInt64)
+		g.addEdge(id, Sink, Infinite)
 	}
 	return id
 }
 
-// AddEdge sets the capacity of an edge in the flow network. Adding an edge twice has no additional effect.
-// Attempting to use flownet.Source as toId or flownet.Sink as fromID yields an error. An error is returned
-// if either fromID or toID are not valid node IDs.
-func (g *FlowNetwork) AddEdge(fromID, toID int, capacity int64) error {
+// AddEdge sets the capacity of an edge in the flow network, and returns an ID which refers to it. Adding an
+// edge twice sets the capacity of the same edge again, and returns the same ID; use AddParallelEdge to join
+// two nodes by several edges. Attempting to use flownet.Source as toId or flownet.Sink as fromID yields an
+// error. An error is returned if either fromID or toID are not valid node IDs. Adding a directed edge
+// between two nodes joined by an undirected edge replaces the undirected edge.
+func (g *FlowNetwork) AddEdge(fromID, toID int, capacity int64) (EdgeID, error) {
+	if err := g.checkEdge(fromID, toID, capacity); err != nil {
+		return -1, err
+	}
+	e := newEdge(g.outHalfID(fromID), toID)
+	if ids := g.parallel[e]; len(ids) > 0 {
+		if err := g.SetCapacity(ids[0], capacity); err != nil {
+			return -1, err
+		}
+		g.connectDirected(fromID, toID)
+		return ids[0], nil
+	}
+	g.connectDirected(fromID, toID)
+	return g.addEdgeByID(e, capacity, capacity), nil
+}
+
+// AddUndirectedEdge sets the capacity of an undirected edge between two nodes in the flow network. Flow may
+// cross the edge in either direction, but the net flow across it may not exceed its capacity. Adding an
+// undirected edge replaces any directed edges between the same two nodes. An error is returned if either
+// u or v is not a valid node ID, or is the source or sink.
+func (g *FlowNetwork) AddUndirectedEdge(u, v int, capacity int64) error {
+	if u == Source || u == Sink || v == Source || v == Sink {
+		return fmt.Errorf("undirected edges cannot be connected to the source or sink pseudonodes")
+	}
+	if err := g.setEdge(u, v, capacity); err != nil {
+		return err
+	}
+	_ = g.setEdge(v, u, capacity)
+	if g.undirected == nil {
+		g.undirected = make(map[edge]struct{})
+	}
+	g.undirected[undirectedEdge(u, v)] = struct{}{}
+	return nil
+}
+
+// setEdge sets the total capacity of the edges between two nodes, which are added if they do not exist.
+// If some of the edges have IDs, the first of those is given the capacity, and the rest none.
+func (g *FlowNetwork) setEdge(fromID, toID int, capacity int64) error {
+	if err := g.checkEdge(fromID, toID, capacity); err != nil {
+		return err
+	}
+	g.connect(fromID, toID)
+	// edges leaving a node with a node capacity leave its out-half.
+	g.setPairCapacity(newEdge(g.outHalfID(fromID), toID), capacity)
+	return nil
+}
+
+// connectDirected readies the network for a new directed edge between two nodes, as for connect. Any
+// undirected edge joining the nodes is replaced, leaving only the directed edge.
+func (g *FlowNetwork) connectDirected(fromID, toID int) {
+	g.connect(fromID, toID)
+	if _, ok := g.undirected[undirectedEdge(fromID, toID)]; ok {
+		delete(g.undirected, undirectedEdge(fromID, toID))
+		g.setPairCapacity(newEdge(g.outHalfID(toID), fromID), 0)
+	}
+}
+
+// checkEdge returns an error if an edge with the provided capacity cannot join the provided nodes.
+func (g *FlowNetwork) checkEdge(fromID, toID int, capacity int64) error {
 	if fromID == toID {
 		return fmt.Errorf("self-loops are not allowed, found one with %d -> %d", fromID, toID)
 	}
@@ -193,31 +285,51 @@ func (g *FlowNetwork) AddEdge(fromID, toID int, capacity int64) error {
 	if capacity < 0 {
 		return fmt.Errorf("capacities must be non-negative")
 	}
+	return nil
+}
+
+// connect readies the network for a new edge between two nodes. Connections to the source or sink become
+// managed manually if the edge joins them, and any default connections which the edge replaces are removed.
+func (g *FlowNetwork) connect(fromID, toID int) {
 	if fromID == Source {
 		g.enableManualSource()
 	}
 	if toID == Sink {
 		g.enableManualSink()
 	}
-
-	// actually set the capacity! woo! (finally)
-	g.addEdge(fromID, toID, capacity)
+	fromID = g.outHalfID(fromID)
 
 	// auto-remove any connections from/to the source/sink pseudonodes (if they're managed automatically)
 	if !g.manualSource {
-		delete(g.capacity, edge{sourceID, toID + 2})
-		delete(g.adjacencyList[sourceID], toID+2)
+		g.disjoin(edge{sourceID, toID + 2})
 	}
 	if !g.manualSink {
-		delete(g.capacity, edge{fromID + 2, sinkID})
-		delete(g.adjacencyList[fromID+2], sinkID)
+		g.disjoin(edge{fromID + 2, sinkID})
 	}
-	return nil
 }
 
 func (g *FlowNetwork) addEdge(fromID, toID int, capacity int64) {
 	g.capacity[edge{fromID + 2, toID + 2}] = capacity
-	g.adjacencyList[fromID+2][toID+2] = struct{}{}
+	g.join(edge{fromID + 2, toID + 2})
+}
+
+// join adds the nodes of e to the adjacency list, by internal IDs. The adjacency visit list is rebuilt by the
+// next call to prepare if they were not already adjacent.
+func (g *FlowNetwork) join(e edge) {
+	if _, ok := g.adjacencyList[e.from][e.to]; !ok {
+		g.adjacencyList[e.from][e.to] = struct{}{}
+		g.adjacencyVisitList = nil
+	}
+}
+
+// disjoin removes e and its capacity from the network, by internal IDs. The adjacency visit list is rebuilt
+// by the next call to prepare if e was present.
+func (g *FlowNetwork) disjoin(e edge) {
+	delete(g.capacity, e)
+	if _, ok := g.adjacencyList[e.from][e.to]; ok {
+		delete(g.adjacencyList[e.from], e.to)
+		g.adjacencyVisitList = nil
+	}
 
 }
 
@@ -234,20 +346,29 @@ func (g *FlowNetwork) SetNodeOrder(nodeIDs []int) error {
 		return fmt.Errorf("wrong number of nodeIDs; expected exactly %d of them", g.numNodes)
 	}
 	ids := make(map[int]struct{})
-	mappedIds := make([]int, g.numNodes)
+	n := g.numInternalNodes() - 2
+	mappedIds := make([]int, n)
 
-	for i, id := range nodeIDs {
+	i := 0
+	for _, id := range nodeIDs {
 		if id < 0 || id >= g.numNodes {
 			return fmt.Errorf("unknown node ID %d", id)
 		}
+		if _, ok := ids[id]; ok {
+			return fmt.Errorf("duplicate nodeIDs were present, saw %d more than once", id)
+		}
 		ids[id] = struct{}{}
 		// reverse the nodeIDs here, since PushRelabel's queue runs backwards
-		mappedIds[g.numNodes-1-i] = internalID(id)
-	}
-	if len(ids) != g.numNodes {
-		return fmt.Errorf("duplicate nodeIDs were present, saw %d unique ids", len(ids))
+		mappedIds[n-1-i] = internalID(id)
+		i++
+		// hidden out-halves are discharged just after the node they belong to.
+		if h, ok := g.outHalf[internalID(id)]; ok {
+			mappedIds[n-1-i] = h
+			i++
+		}
 	}
 	g.nodeOrder = mappedIds
+	g.adjacencyVisitList = nil
 	return nil
 }
 
@@ -255,16 +376,50 @@ func (g *FlowNetwork) SetNodeOrder(nodeIDs []int) error {
 // specifically, PushRelabel visits each node in the network in the node order and attempts to discharges
 // excess flow from the node. This may update the node's label. When a node's label changes as a result of
 // the algorithm, it is moved to the front of the node order, and all nodes are visited once more.
+//
+// If some node cannot be relabeled, PushRelabel stops early. If the capacities are too large to combine
+// without overflowing, or the flow is unbounded, no flow is found at all. Use Solve to find out whether
+// either of these happened.
 func (g *FlowNetwork) PushRelabel() {
+	_ = g.Solve()
+}
+
+// Solve finds a maximum flow via the same algorithm as PushRelabel, returning an error if the algorithm
+// could not be completed. See SolveContext for the errors which may be returned.
+func (g *FlowNetwork) Solve() error {
+	return g.SolveContext(context.Background(), SolveOptions{})
+}
+
+// pushRelabel implements PushRelabel, reporting its work to the provided monitor.
+func (g *FlowNetwork) pushRelabel(m *monitor) error {
 	// implementation based heavily on these notes:
 	// https://www.ccs.neu.edu/home/vip/teach/Algorithms/11_graphsC_networks/push_relabel.pdf
-	g.reset() // TODO: this makes it impossible to 'reflow'.
-	nodeQueue := append(make([]int, 0, g.numNodes), g.nodeOrder...)
+	// push-relabel always starts from scratch; Resolve reuses the flow found by the last solve instead.
+	if err := g.reset(); err != nil {
+		return err
+	}
+	// preparing a large network takes a while, so check for cancellation before discharging any node.
+	if err := m.ctx.Err(); err != nil {
+		return err
+	}
+	a := g.compile()
+	err := g.relabelToFront(a, m)
+	g.writeBack(a)
+	return err
+}
+
+// relabelToFront discharges each node in the node order, moving each node which is relabeled to the front,
+// until no node holds any excess. The flow is found in the provided arcGraph, which must have been compiled
+// from g.
+func (g *FlowNetwork) relabelToFront(a *arcGraph, m *monitor) error {
+	nodeQueue := append(make([]int, 0, len(g.nodeOrder)), g.nodeOrder...)
 	p := len(nodeQueue) - 1
 	for p >= 0 {
 		u := nodeQueue[p]
 		oldLabel := g.label[u]
-		g.discharge(u)
+		if err := g.discharge(a, u, m); err != nil {
+			return err
+		}
 		if g.label[u] > oldLabel {
 			nodeQueue = append(nodeQueue[:p], nodeQueue[p+1:]...)
 			nodeQueue = append(nodeQueue, u)
@@ -273,102 +428,185 @@ func (g *FlowNetwork) PushRelabel() {
 			p--
 		}
 	}
+	m.done()
+	return nil
 }
 
-// push moves as much excess flow across the provided edge as possible without violating the edge's capacity
-// constraint.
-func (g *FlowNetwork) push(e edge) {
-	delta := min64(g.excess[e.from], g.residual(e))
-	if g.capacity[e] > 0 {
-		g.preflow[e] += delta
-	} else {
-		g.preflow[e.reverse()] -= delta
-	}
-	g.excess[e.from] -= delta
-	g.excess[e.to] += delta
+// A RelabelError reports a node which held excess flow, but which could not be relabeled because none
+// of its edges had any residual capacity. It describes the state of the node when the error occurred.
+type RelabelError struct {
+	// NodeID is the ID of the node which could not be relabeled.
+	NodeID int
+	// Label is the label of the node.
+	Label int
+	// Excess is the excess flow held by the node.
+	Excess int64
+}
+
+func (e *RelabelError) Error() string {
+	return fmt.Sprintf("could not relabel node %d with label %d and excess %d: no edge has residual capacity", e.NodeID, e.Label, e.Excess)
+}
+
+// push moves as much excess flow from nodeID across the provided arc as possible without violating the
+// arc's capacity constraint.
+func (g *FlowNetwork) push(a *arcGraph, nodeID, arc int) {
+	delta := min64(g.excess[nodeID], a.residual[arc])
+	a.push(arc, delta)
+	g.excess[nodeID] -= delta
+	g.excess[a.head[arc]] += delta
 }
 
 // relabel increases the label of an node with no excess to one larger than the minimum of its neighbors.
-func (g *FlowNetwork) relabel(nodeID int) {
+// An error is returned if none of the node's edges have residual capacity.
+func (g *FlowNetwork) relabel(a *arcGraph, nodeID int) error {
 	minHeight := math.MaxInt32 - 1
-	for _, u := range g.adjacencyVisitList[nodeID] {
-		if g.residual(edge{nodeID, u}) > 0 {
-			minHeight = min(minHeight, g.label[u])
+	for arc := a.first[nodeID]; arc < a.first[nodeID+1]; arc++ {
+		if a.residual[arc] > 0 {
+			minHeight = min(minHeight, g.label[a.head[arc]])
 			g.label[nodeID] = minHeight + 1
 		}
 	}
 	if minHeight+1 == math.MaxInt32 {
-		// TODO: don't panic here, the client may disapprove.
-		log.Fatalf("could not relabel node %d", nodeID-2)
+		return &RelabelError{NodeID: externalID(nodeID), Label: g.label[nodeID], Excess: g.excess[nodeID]}
 	}
+	return nil
 }
 
-// discharge pushes as much excess from nodeID to its unseen neighbors as possible.
-func (g *FlowNetwork) discharge(nodeID int) {
+// discharge pushes as much excess from nodeID to its unseen neighbors in the provided arcGraph as possible.
+func (g *FlowNetwork) discharge(a *arcGraph, nodeID int, m *monitor) error {
 	for g.excess[nodeID] > 0 {
-		if g.seen[nodeID] == len(g.adjacencyVisitList[nodeID]) {
-			g.relabel(nodeID)
+		if err := m.step(); err != nil {
+			return err
+		}
+		if g.seen[nodeID] == a.first[nodeID+1]-a.first[nodeID] {
+			if err := g.relabel(a, nodeID); err != nil {
+				return err
+			}
+			m.relabeled()
 			g.seen[nodeID] = 0
 		} else {
-			v := g.adjacencyVisitList[nodeID][g.seen[nodeID]]
-			e := edge{nodeID, v}
-			if g.residual(e) > 0 && g.label[nodeID] == g.label[v]+1 {
-				g.push(e)
+			arc := a.first[nodeID] + g.seen[nodeID]
+			if a.residual[arc] > 0 && g.label[nodeID] == g.label[a.head[arc]]+1 {
+				g.push(a, nodeID, arc)
 			} else {
 				g.seen[nodeID]++
 			}
 		}
 	}
+	return nil
 }
 
-// reset prepares the network for computing a new flow.
-func (g *FlowNetwork) reset() {
-	if len(g.nodeOrder) != g.numNodes {
-		g.nodeOrder = make([]int, 0, g.numNodes)
-		for i := 0; i < g.numNodes; i++ {
-			g.nodeOrder = append(g.nodeOrder, g.numNodes-1-i+2)
+// reset prepares the network for computing a new flow via push-relabel.
+func (g *FlowNetwork) reset() error {
+	g.clearFlow()
+	if err := g.prepare(); err != nil {
+		return err
+	}
+	g.label[sourceID] = g.numInternalNodes()
+	// set the excess and flow for edges leading out from the source. No node can send on more flow than its
+	// outgoing capacity, so the flow is limited to the outgoing capacity of the node each edge enters.
+	totalCapacity := int64(0)
+	for u := 2; u < g.numNodes+2; u++ {
+		if _, ok := g.capacity[edge{sourceID, u}]; !ok {
+			continue
+		}
+		flow := min64(g.effectiveCapacity(edge{sourceID, u}), g.outgoingCapacity(u))
+		if flow == Infinite {
+			flow = g.infinity
 		}
+		var ok bool
+		if totalCapacity, ok = addCapacity(totalCapacity, flow); !ok {
+			g.clearFlow()
+			return fmt.Errorf("%w: the total flow leaving the source is too large for push-relabel", ErrCapacityOverflow)
+		}
+
+		g.excess[u] = flow
+		g.preflow[edge{sourceID, u}] = flow
 	}
-	// construct an adjacency visit list that is compatible with nodeOrder (since nodeOrder may have changed.)
-	g.adjacencyVisitList = make([][]int, len(g.adjacencyList))
-	for u := range g.adjacencyList {
-		// TODO: we don't need to do this if the nodeOrder or set of nodes _hasn't_ changed.
-		for _, v := range append(g.nodeOrder, []int{sourceID, sinkID}...) {
-			_, ok1 := g.adjacencyList[u][v]
-			_, ok2 := g.adjacencyList[v][u]
-			if ok1 || ok2 {
-				g.adjacencyVisitList[u] = append([]int{v}, g.adjacencyVisitList[u]...)
+	g.excess[sourceID] = -totalCapacity
+	return nil
+}
+
+// prepare readies the network for any of the max-flow algorithms, without touching the flow. An error is
+// returned if the flow cannot be found without overflowing, or if it is unbounded.
+func (g *FlowNetwork) prepare() error {
+	if err := g.checkCapacities(); err != nil {
+		return err
+	}
+	if len(g.nodeOrder) != g.numInternalNodes()-2 {
+		// nodeOrder runs backwards; nodes are discharged in order of ID, each followed by its hidden out-half.
+		g.nodeOrder = make([]int, 0, g.numInternalNodes()-2)
+		for u := g.numNodes + 1; u >= 2; u-- {
+			if h, ok := g.outHalf[u]; ok {
+				g.nodeOrder = append(g.nodeOrder, h)
 			}
+			g.nodeOrder = append(g.nodeOrder, u)
 		}
+		g.adjacencyVisitList = nil
 	}
-	g.label[sourceID] = g.numNodes + 2
-	g.label[sinkID] = 0
-	for i := 0; i < g.numNodes; i++ {
-		g.label[internalID(i)] = 0
-	}
-	for e := range g.preflow {
-		g.preflow[e] = 0
+	if len(g.adjacencyVisitList) != len(g.adjacencyList) {
+		g.buildVisitList()
 	}
-	// set the capacity, excess, and flow for edges leading out from from source; using the max outgoing capacity of any node adjacent to source.
-	totalCapacity := int64(0)
-	for u := 2; u < g.numNodes+2; u++ {
-		if _, ok := g.capacity[edge{sourceID, u}]; !ok {
-			continue
+	// edges leading out from the source are managed automatically; no node can send on more flow than its
+	// outgoing capacity, so that is all the capacity it needs.
+	if !g.manualSource {
+		for u := 2; u < g.numNodes+2; u++ {
+			if _, ok := g.capacity[edge{sourceID, u}]; ok {
+				g.capacity[edge{sourceID, u}] = g.outgoingCapacity(u)
+			}
 		}
-		outgoingCapacity := int64(0)
+	}
+	return g.checkBounded()
+}
+
+// buildVisitList constructs an adjacency visit list that is compatible with nodeOrder. Each node visits its
+// neighbors in reverse order of their position in nodeOrder, after the sink and source. The list is kept
+// until the nodes, their order, or the pairs of adjacent nodes change.
+func (g *FlowNetwork) buildVisitList() {
+	position := make([]int, len(g.adjacencyList))
+	for i, u := range g.nodeOrder {
+		position[u] = i
+	}
+	position[sourceID] = len(g.nodeOrder)
+	position[sinkID] = len(g.nodeOrder) + 1
+	g.adjacencyVisitList = make([][]int, len(g.adjacencyList))
+	for u := range g.adjacencyList {
 		for v := range g.adjacencyList[u] {
-			if v == sinkID || v == sourceID {
-				continue
+			g.adjacencyVisitList[u] = append(g.adjacencyVisitList[u], v)
+			if _, ok := g.adjacencyList[v][u]; !ok {
+				g.adjacencyVisitList[v] = append(g.adjacencyVisitList[v], u)
 			}
-			outgoingCapacity += g.capacity[edge{u, v}]
 		}
-		totalCapacity += outgoingCapacity
+	}
+	for _, neighbors := range g.adjacencyVisitList {
+		sort.Slice(neighbors, func(i, j int) bool { return position[neighbors[i]] > position[neighbors[j]] })
+	}
+}
+
+// outgoingCapacity returns the total capacity of the edges leaving nodeID, which is Infinite if any of them
+// has infinite capacity. Edges to the sink are only counted if they are managed manually. checkCapacities
+// must have been called first, so that the total cannot overflow.
+func (g *FlowNetwork) outgoingCapacity(nodeID int) int64 {
+	result := int64(0)
+	for v := range g.adjacencyList[nodeID] {
+		if v == sourceID || (v == sinkID && !g.manualSink) {
+			continue
+		}
+		result, _ = addCapacity(result, g.capacity[edge{nodeID, v}])
+	}
+	return result
+}
 
-		g.capacity[edge{sourceID, u}] = outgoingCapacity
-		g.excess[u] = outgoingCapacity
-		g.preflow[edge{sourceID, u}] = outgoingCapacity
+// clearFlow removes all flow, excess, and labels from the network.
+func (g *FlowNetwork) clearFlow() {
+	for e := range g.preflow {
+		g.preflow[e] = 0
+	}
+	for u := range g.label {
+		g.label[u] = 0
+		g.excess[u] = 0
+		g.seen[u] = 0
 	}
-	g.excess[sourceID] = -totalCapacity
 }
 
 func (g *FlowNetwork) enableManualSource() {
@@ -378,8 +616,7 @@ func (g *FlowNetwork) enableManualSource() {
 	g.manualSource = true
 	// disconnect all nodes from source and sink; programmer wants to do it themselves.
 	for i := 2; i < g.numNodes+2; i++ {
-		delete(g.capacity, edge{sourceID, i})
-		delete(g.adjacencyList[sourceID], i)
+		g.disjoin(edge{sourceID, i})
 	}
 }
 
@@ -388,10 +625,10 @@ func (g *FlowNetwork) enableManualSink() {
 		return
 	}
 	g.manualSink = true
-	// disconnect all nodes from source and sink; programmer wants to do it themselves.
-	for i := 2; i < g.numNodes+2; i++ {
-		delete(g.capacity, edge{i, sinkID})
-		delete(g.adjacencyList[i], sinkID)
+	// disconnect all nodes from source and sink; programmer wants to do it themselves. Hidden out-halves
+	// may be joined to the sink too.
+	for i := 2; i < g.numInternalNodes(); i++ {
+		g.disjoin(edge{i, sinkID})
 	}
 }
 
@@ -399,7 +636,7 @@ func (g *FlowNetwork) enableManualSink() {
 // nodes connected to the source, using the provided less function to break any ties that are found.
 // if the flow network is not a DAG (which is allowed) this function will report an error.
 func TopSort(fn FlowNetwork, less func(int, int) bool) ([]int, error) {
-	unvisitedEdges := make([]map[int]struct{}, fn.numNodes+2) // list of nodeIDs to the set of their of incoming nodes
+	unvisitedEdges := make([]map[int]struct{}, fn.numInternalNodes()) // list of nodeIDs to the set of their of incoming nodes
 	for edge, capacity := range fn.capacity {
 		if capacity <= 0 {
 			continue
@@ -417,7 +654,7 @@ func TopSort(fn FlowNetwork, less func(int, int) bool) ([]int, error) {
 	result := make([]int, 0, fn.numNodes)
 	for roots.Len() > 0 {
 		next := roots.Pop().(int)
-		if next != sourceID && next != sinkID {
+		if next != sourceID && next != sinkID && !fn.isHidden(next) {
 			result = append(result, next-2)
 		}
 		for neighbor := range fn.adjacencyList[next] {
@@ -27,7 +27,7 @@ func TestAddEdge(t *testing.T) {
 		{0, 1, -1, true},
 	}
 	for _, test := range tests {
-		err := g.AddEdge(test.fromID, test.toID, test.capacity)
+		_, err := g.AddEdge(test.fromID, test.toID, test.capacity)
 		if err == nil && test.expectedErr {
 			t.Errorf("expected error when adding edge %d -> %d with capacity %d", test.fromID, test.toID, test.capacity)
 		}
@@ -41,7 +41,7 @@ func TestSanityAllFlowNetworks(t *testing.T) {
 	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
 		graph := flownet.NewFlowNetwork(instance.numNodes)
 		for edge, cap := range instance.capacities {
-			if err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
+			if _, err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
 				t.Error(err)
 			}
 		}
@@ -70,7 +70,7 @@ func TestTopSortAllFlowNetworks(t *testing.T) {
 	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
 		graph := flownet.NewFlowNetwork(instance.numNodes)
 		for edge, cap := range instance.capacities {
-			if err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
+			if _, err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
 				t.Error(err)
 			}
 		}
@@ -97,3 +97,110 @@ func TestTopSortAllFlowNetworks(t *testing.T) {
 		return nil
 	})
 }
+
+func TestSolveAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if _, err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
+				t.Error(err)
+			}
+		}
+		if err := graph.Solve(); err != nil {
+			t.Errorf("failed test %s: unexpected error from Solve: %v", path, err)
+			return nil
+		}
+		if instance.expectedFlow != -1 && instance.expectedFlow != graph.Outflow() {
+			t.Errorf("failed test %s expected max-flow of %d but was %d", path, instance.expectedFlow, graph.Outflow())
+		}
+		return nil
+	})
+}
+
+func TestAddUndirectedEdge(t *testing.T) {
+	solvers := map[string]func(*flownet.FlowNetwork) error{
+		"PushRelabel":             (*flownet.FlowNetwork).Solve,
+		"PushRelabelHighestLabel": (*flownet.FlowNetwork).PushRelabelHighestLabel,
+		"Dinic":                   (*flownet.FlowNetwork).Dinic,
+		"BoykovKolmogorov":        (*flownet.FlowNetwork).BoykovKolmogorov,
+	}
+	for name, solve := range solvers {
+		// flow from the source enters at 0 and 3, and must cross the undirected edge between 1 and 2 to
+		// reach the sink.
+		g := flownet.NewFlowNetwork(4)
+		g.AddEdge(flownet.Source, 0, 10)
+		g.AddEdge(0, 2, 10)
+		g.AddEdge(1, flownet.Sink, 10)
+		if err := g.AddUndirectedEdge(1, 2, 4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := solve(&g); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if g.Outflow() != 4 {
+			t.Errorf("%s: expected outflow of 4, found %d", name, g.Outflow())
+		}
+		if g.Flow(2, 1) != 4 || g.Flow(1, 2) != -4 {
+			t.Errorf("%s: expected net flow of 4 from 2 to 1, found %d and %d", name, g.Flow(2, 1), g.Flow(1, 2))
+		}
+		if g.Capacity(1, 2) != 4 || g.Capacity(2, 1) != 4 {
+			t.Errorf("%s: expected capacity of 4 in both directions, found %d and %d", name, g.Capacity(1, 2), g.Capacity(2, 1))
+		}
+		if err := flownet.SanityChecks.FlowNetwork(g, true); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+}
+
+func TestAddUndirectedEdge_Replace(t *testing.T) {
+	g := flownet.NewFlowNetwork(2)
+	if err := g.AddUndirectedEdge(flownet.Source, 0, 1); err == nil {
+		t.Errorf("expected an error when connecting an undirected edge to the source")
+	}
+	if err := g.AddUndirectedEdge(0, 2, 1); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+	g.AddEdge(flownet.Source, 0, 10)
+	g.AddEdge(1, flownet.Sink, 10)
+	g.AddUndirectedEdge(0, 1, 5)
+	g.AddEdge(0, 1, 3)
+	if g.Capacity(0, 1) != 3 || g.Capacity(1, 0) != 0 {
+		t.Errorf("expected a directed edge of capacity 3, found capacities %d and %d", g.Capacity(0, 1), g.Capacity(1, 0))
+	}
+	g.Dinic()
+	if g.Flow(0, 1) != 3 || g.Flow(1, 0) != 0 {
+		t.Errorf("expected flow of 3 from 0 to 1, found %d and %d", g.Flow(0, 1), g.Flow(1, 0))
+	}
+}
+
+func TestPushRelabel_ManualSource(t *testing.T) {
+	// the capacity of a manually added source edge must limit the flow, even when the node it enters could
+	// send on more.
+	g := flownet.NewFlowNetwork(3)
+	g.AddEdge(flownet.Source, 0, 3)
+	g.AddEdge(0, 1, 10)
+	g.AddEdge(1, 2, 10)
+	g.PushRelabel()
+	if g.Outflow() != 3 {
+		t.Errorf("expected a max flow of 3 limited by the source edge, found %d", g.Outflow())
+	}
+}
+
+func TestResidual_AntiparallelEdges(t *testing.T) {
+	// flow along 0 -> 1 can be cancelled, so it counts toward the residual of 1 -> 0.
+	g := flownet.NewFlowNetwork(2)
+	g.AddEdge(flownet.Source, 0, 5)
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(1, 0, 2)
+	g.AddEdge(1, flownet.Sink, 5)
+	g.PushRelabel()
+	if g.Flow(0, 1) != 5 {
+		t.Fatalf("expected a flow of 5 along 0 -> 1, found %d", g.Flow(0, 1))
+	}
+	if g.Residual(1, 0) != 2+5 {
+		t.Errorf("expected a residual of 7 along 1 -> 0, found %d", g.Residual(1, 0))
+	}
+	if err := flownet.SanityChecks.FlowNetwork(g, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+package flownet
+
+import "fmt"
+
+// A GomoryHuTree is a tree on the nodes of an UndirectedGraph which encodes a minimum cut between every
+// pair of nodes. The capacity of a minimum cut between u and v is the smallest capacity of any tree edge on
+// the path from u to v, and removing that edge from the tree splits the nodes into the two sides of such a
+// cut.
+type GomoryHuTree struct {
+	// parent stores the parent of each node in the tree. The root, node 0, is its own parent.
+	parent []int
+	// capacity stores the capacity of the edge between each node and its parent.
+	capacity []int64
+	// depth stores the number of edges between each node and the root.
+	depth []int
+}
+
+// GomoryHuTree builds a Gomory-Hu tree of the graph via Gusfield's algorithm, which finds a maximum flow
+// between n-1 pairs of nodes without contracting the graph. An error is returned if a path of edges with
+// Infinite capacity joins some pair of nodes, or if the capacities are too large to combine without
+// overflowing.
+func (g UndirectedGraph) GomoryHuTree() (GomoryHuTree, error) {
+	n := g.numNodes
+	tree := GomoryHuTree{
+		parent:   make([]int, n),
+		capacity: make([]int64, n),
+		depth:    make([]int, n),
+	}
+	for s := 1; s < n; s++ {
+		t := tree.parent[s]
+		cut, err := g.minCut(s, t)
+		if err != nil {
+			return GomoryHuTree{}, fmt.Errorf("could not find a minimum cut between nodes %d and %d: %w", s, t, err)
+		}
+		sideA := make([]bool, n)
+		for _, u := range cut.SideA {
+			sideA[u] = true
+		}
+		tree.capacity[s] = cut.Capacity
+		for u := 0; u < n; u++ {
+			if u != s && sideA[u] && tree.parent[u] == t {
+				tree.parent[u] = s
+			}
+		}
+		// if the cut also separates t from its own parent, s takes the place of t in the tree.
+		if t != 0 && sideA[tree.parent[t]] {
+			tree.parent[s], tree.parent[t] = tree.parent[t], s
+			tree.capacity[s], tree.capacity[t] = tree.capacity[t], cut.Capacity
+		}
+	}
+	for u := 0; u < n; u++ {
+		tree.depth[u] = tree.depthOf(u)
+	}
+	return tree, nil
+}
+
+// depthOf computes the depth of nodeID by following parents to the root.
+func (t GomoryHuTree) depthOf(nodeID int) int {
+	depth := 0
+	for ; nodeID != 0; nodeID = t.parent[nodeID] {
+		depth++
+	}
+	return depth
+}
+
+// Parent returns the parent of the provided node in the tree, along with the capacity of the minimum cut
+// between the node and its parent. Returns false if nodeID is the root or is not a valid node ID.
+func (t GomoryHuTree) Parent(nodeID int) (parent int, capacity int64, ok bool) {
+	if nodeID <= 0 || nodeID >= len(t.parent) {
+		return 0, 0, false
+	}
+	return t.parent[nodeID], t.capacity[nodeID], true
+}
+
+// MinCut returns a minimum cut separating u from v, with u in SideA and v in SideB. An error is returned if
+// u and v are the same node, or if either is not a valid node ID.
+func (t GomoryHuTree) MinCut(u, v int) (UndirectedCut, error) {
+	if u == v {
+		return UndirectedCut{}, fmt.Errorf("no cut separates node %d from itself", u)
+	}
+	if u < 0 || u >= len(t.parent) {
+		return UndirectedCut{}, fmt.Errorf("no node with ID %d is known", u)
+	}
+	if v < 0 || v >= len(t.parent) {
+		return UndirectedCut{}, fmt.Errorf("no node with ID %d is known", v)
+	}
+	// find the lightest tree edge on the path from u to v, identified by the node below it.
+	lightest := -1
+	for x, y := u, v; x != y; {
+		if t.depth[x] < t.depth[y] {
+			x, y = y, x
+		}
+		if lightest == -1 || t.capacity[x] < t.capacity[lightest] {
+			lightest = x
+		}
+		x = t.parent[x]
+	}
+	// nodes below the lightest edge lie on one side of the cut; the rest lie on the other.
+	children := make([][]int, len(t.parent))
+	for x := 1; x < len(t.parent); x++ {
+		children[t.parent[x]] = append(children[t.parent[x]], x)
+	}
+	below := make([]bool, len(t.parent))
+	below[lightest] = true
+	for stack := []int{lightest}; len(stack) > 0; {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, y := range children[x] {
+			below[y] = true
+			stack = append(stack, y)
+		}
+	}
+	var cut UndirectedCut
+	for x := range t.parent {
+		if below[x] == below[u] {
+			cut.SideA = append(cut.SideA, x)
+		} else {
+			cut.SideB = append(cut.SideB, x)
+		}
+	}
+	cut.Capacity = t.capacity[lightest]
+	return cut, nil
+}
@@ -0,0 +1,135 @@
+package flownet_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestGomoryHuTree_AllPairs(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		n := 2 + r.Intn(10)
+		graph := randomUndirectedGraph(r, n)
+		tree, err := graph.GomoryHuTree()
+		if err != nil {
+			t.Fatalf("trial %d: unexpected error: %v", trial, err)
+		}
+		for u := 0; u < n; u++ {
+			for v := 0; v < n; v++ {
+				if u == v {
+					continue
+				}
+				cut, err := tree.MinCut(u, v)
+				if err != nil {
+					t.Fatalf("trial %d: unexpected error: %v", trial, err)
+				}
+				if expected := undirectedMaxFlow(graph, n, u, v); cut.Capacity != expected {
+					t.Errorf("trial %d: expected min cut of %d between %d and %d, found %d", trial, expected, u, v, cut.Capacity)
+				}
+				if cutCapacity(graph, cut) != cut.Capacity {
+					t.Errorf("trial %d: partition between %d and %d has capacity %d, not %d", trial, u, v, cutCapacity(graph, cut), cut.Capacity)
+				}
+				if !containsNode(cut.SideA, u) || !containsNode(cut.SideB, v) {
+					t.Errorf("trial %d: cut %v does not separate %d from %d", trial, cut, u, v)
+				}
+			}
+		}
+	}
+}
+
+func TestGomoryHuTree_Parent(t *testing.T) {
+	graph := flownet.NewUndirectedGraph(3)
+	graph.AddEdge(0, 1, 4)
+	graph.AddEdge(1, 2, 2)
+	tree, err := graph.GomoryHuTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, _, ok := tree.Parent(0); ok {
+		t.Errorf("expected the root to have no parent")
+	}
+	total := int64(0)
+	for u := 1; u < 3; u++ {
+		_, capacity, ok := tree.Parent(u)
+		if !ok {
+			t.Errorf("expected node %d to have a parent", u)
+		}
+		total += capacity
+	}
+	if total != 6 {
+		t.Errorf("expected tree edges with total capacity 6, found %d", total)
+	}
+}
+
+func TestGomoryHuTree_Errors(t *testing.T) {
+	graph := flownet.NewUndirectedGraph(2)
+	graph.AddEdge(0, 1, flownet.Infinite)
+	if _, err := graph.GomoryHuTree(); !errors.Is(err, flownet.ErrUnboundedFlow) {
+		t.Errorf("expected ErrUnboundedFlow, found %v", err)
+	}
+	graph.AddEdge(0, 1, 3)
+	tree, err := graph.GomoryHuTree()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := tree.MinCut(1, 1); err == nil {
+		t.Errorf("expected an error when cutting a node from itself")
+	}
+	if _, err := tree.MinCut(0, 2); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
+
+// randomUndirectedGraph returns a random connected undirected graph with n nodes.
+func randomUndirectedGraph(r *rand.Rand, n int) flownet.UndirectedGraph {
+	graph := flownet.NewUndirectedGraph(n)
+	for v := 1; v < n; v++ {
+		graph.AddEdge(r.Intn(v), v, 1+r.Int63n(10))
+	}
+	for i := 0; i < n; i++ {
+		u, v := r.Intn(n), r.Intn(n)
+		if u != v {
+			graph.AddEdge(u, v, r.Int63n(10))
+		}
+	}
+	return graph
+}
+
+// undirectedMaxFlow returns the value of a maximum flow from s to t in the provided graph.
+func undirectedMaxFlow(graph flownet.UndirectedGraph, n, s, t int) int64 {
+	fn := flownet.NewFlowNetwork(n)
+	fn.AddEdge(flownet.Source, s, flownet.Infinite)
+	fn.AddEdge(t, flownet.Sink, flownet.Infinite)
+	for u := 0; u < n; u++ {
+		for v := 0; v < n; v++ {
+			if u != v && graph.Capacity(u, v) > 0 {
+				fn.AddEdge(u, v, graph.Capacity(u, v))
+			}
+		}
+	}
+	fn.PushRelabel()
+	return fn.Outflow()
+}
+
+// cutCapacity returns the total capacity of the edges crossing the provided cut.
+func cutCapacity(graph flownet.UndirectedGraph, cut flownet.UndirectedCut) int64 {
+	result := int64(0)
+	for _, u := range cut.SideA {
+		for _, v := range cut.SideB {
+			result += graph.Capacity(u, v)
+		}
+	}
+	return result
+}
+
+func containsNode(nodeIDs []int, nodeID int) bool {
+	for _, u := range nodeIDs {
+		if u == nodeID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,240 @@
+package flownet
+
+// PushRelabelHighestLabel finds a maximum flow via the highest-label variant of the push-relabel algorithm.
+// Rather than visiting nodes in the node order, the active node with the largest label is always
+// discharged first. Node labels are initialized by a breadth-first search backwards from the sink, and
+// this global relabeling is repeated periodically. Whenever no node remains at some label below the
+// number of nodes, every node above that gap is cut off from the sink and is relabeled at once.
+//
+// PushRelabelHighestLabel is usually much faster than PushRelabel on large networks. The node order set
+// via SetNodeOrder is not used. Errors are returned as by Solve: if some node cannot be relabeled,
+// PushRelabelHighestLabel stops early and returns a *RelabelError, and no flow is found if the capacities
+// would overflow or the flow is unbounded.
+func (g *FlowNetwork) PushRelabelHighestLabel() error {
+	if err := g.reset(); err != nil {
+		return err
+	}
+	h := newHighestLabel(g.compile(), g.excess)
+	err := h.run()
+	g.writeBack(h.arcs)
+	copy(g.excess, h.excess)
+	copy(g.label, h.label)
+	return err
+}
+
+// highestLabel stores the state of the highest-label push-relabel algorithm.
+type highestLabel struct {
+	arcs *arcGraph
+	// n is the total number of nodes, including the source and sink.
+	n int
+	// label and excess store the label and excess of each node.
+	label  []int
+	excess []int64
+	// current stores the next arc to visit while discharging each node.
+	current []int
+	// active stores the nodes which may have excess, bucketed by label. Entries may be stale.
+	active [][]int
+	// highest is the largest label which may have an active node.
+	highest int
+	// first, next, and prev form a doubly-linked list of the nodes having each label below n.
+	first, next, prev []int
+	// relabels counts the number of relabel operations since the last global relabel.
+	relabels int
+}
+
+func newHighestLabel(arcs *arcGraph, excess []int64) *highestLabel {
+	n := arcs.numNodes()
+	h := &highestLabel{
+		arcs:    arcs,
+		n:       n,
+		label:   make([]int, n),
+		excess:  make([]int64, n),
+		current: make([]int, n),
+		active:  make([][]int, 2*n),
+		first:   make([]int, n),
+		next:    make([]int, n),
+		prev:    make([]int, n),
+	}
+	copy(h.excess, excess)
+	return h
+}
+
+// run discharges active nodes in order of highest label until no active node remains.
+func (h *highestLabel) run() error {
+	h.globalRelabel()
+	for h.highest >= 0 {
+		bucket := h.active[h.highest]
+		if len(bucket) == 0 {
+			h.highest--
+			continue
+		}
+		u := bucket[len(bucket)-1]
+		h.active[h.highest] = bucket[:len(bucket)-1]
+		if h.label[u] != h.highest || h.excess[u] <= 0 {
+			continue
+		}
+		if err := h.discharge(u); err != nil {
+			return err
+		}
+		if h.relabels >= h.n {
+			h.globalRelabel()
+		}
+	}
+	return nil
+}
+
+// discharge pushes all excess out of nodeID, relabeling it as needed.
+func (h *highestLabel) discharge(nodeID int) error {
+	a := h.arcs
+	for h.excess[nodeID] > 0 {
+		if h.current[nodeID] == a.first[nodeID+1] {
+			if err := h.relabel(nodeID); err != nil {
+				return err
+			}
+			h.current[nodeID] = a.first[nodeID]
+			continue
+		}
+		arc := h.current[nodeID]
+		v := int(a.head[arc])
+		if a.residual[arc] > 0 && h.label[nodeID] == h.label[v]+1 {
+			if h.excess[v] == 0 {
+				h.activate(v)
+			}
+			delta := min64(h.excess[nodeID], a.residual[arc])
+			a.push(arc, delta)
+			h.excess[nodeID] -= delta
+			h.excess[v] += delta
+		} else {
+			h.current[nodeID]++
+		}
+	}
+	return nil
+}
+
+// relabel increases the label of nodeID to one larger than the minimum label of its neighbors in the
+// residual network, and applies the gap heuristic if no node remains at its old label.
+func (h *highestLabel) relabel(nodeID int) error {
+	h.relabels++
+	oldLabel := h.label[nodeID]
+	if oldLabel < h.n {
+		h.unlink(nodeID)
+	}
+	newLabel := -1
+	for arc := h.arcs.first[nodeID]; arc < h.arcs.first[nodeID+1]; arc++ {
+		if v := h.arcs.head[arc]; h.arcs.residual[arc] > 0 && (newLabel == -1 || h.label[v]+1 < newLabel) {
+			newLabel = h.label[v] + 1
+		}
+	}
+	if newLabel == -1 {
+		return &RelabelError{NodeID: externalID(nodeID), Label: oldLabel, Excess: h.excess[nodeID]}
+	}
+	h.label[nodeID] = newLabel
+	if oldLabel >= h.n {
+		return nil
+	}
+	if newLabel < h.n {
+		h.link(nodeID)
+	}
+	if h.first[oldLabel] == -1 {
+		h.gap(oldLabel)
+	}
+	return nil
+}
+
+// gap moves every node with a label between k and n above n, since none of them can reach the sink.
+func (h *highestLabel) gap(k int) {
+	for label := k + 1; label < h.n; label++ {
+		for u := h.first[label]; u != -1; u = h.next[u] {
+			h.label[u] = h.n + 1
+			h.current[u] = h.arcs.first[u]
+			if h.excess[u] > 0 {
+				h.activate(u)
+			}
+		}
+		h.first[label] = -1
+	}
+}
+
+// activate adds nodeID to the bucket of active nodes at its current label.
+func (h *highestLabel) activate(nodeID int) {
+	if nodeID == sourceID || nodeID == sinkID {
+		return
+	}
+	label := h.label[nodeID]
+	h.active[label] = append(h.active[label], nodeID)
+	if label > h.highest {
+		h.highest = label
+	}
+}
+
+// link inserts nodeID into the list of nodes which have its label.
+func (h *highestLabel) link(nodeID int) {
+	label := h.label[nodeID]
+	h.prev[nodeID] = -1
+	h.next[nodeID] = h.first[label]
+	if h.first[label] != -1 {
+		h.prev[h.first[label]] = nodeID
+	}
+	h.first[label] = nodeID
+}
+
+// unlink removes nodeID from the list of nodes which have its label.
+func (h *highestLabel) unlink(nodeID int) {
+	if h.prev[nodeID] != -1 {
+		h.next[h.prev[nodeID]] = h.next[nodeID]
+	} else {
+		h.first[h.label[nodeID]] = h.next[nodeID]
+	}
+	if h.next[nodeID] != -1 {
+		h.prev[h.next[nodeID]] = h.prev[nodeID]
+	}
+}
+
+// globalRelabel sets the label of each node to its distance to the sink in the residual network. Nodes
+// which cannot reach the sink are labeled by n plus their distance to the source.
+func (h *highestLabel) globalRelabel() {
+	h.relabels = 0
+	for u := range h.label {
+		h.label[u] = 2*h.n - 1
+		h.current[u] = h.arcs.first[u]
+	}
+	h.label[sinkID] = 0
+	h.label[sourceID] = h.n
+	h.reverseBFS(sinkID)
+	h.reverseBFS(sourceID)
+
+	for label := range h.first {
+		h.first[label] = -1
+	}
+	for label := range h.active {
+		h.active[label] = h.active[label][:0]
+	}
+	h.highest = -1
+	for u := 2; u < h.n; u++ {
+		if h.label[u] < h.n {
+			h.link(u)
+		}
+		if h.excess[u] > 0 {
+			h.activate(u)
+		}
+	}
+}
+
+// reverseBFS labels every unlabeled node which can reach root in the residual network by its distance
+// from root, offset by the label of root.
+func (h *highestLabel) reverseBFS(root int) {
+	a := h.arcs
+	unlabeled := 2*h.n - 1
+	frontier := []int{root}
+	for len(frontier) > 0 {
+		v := frontier[0]
+		frontier = frontier[1:]
+		for arc := a.first[v]; arc < a.first[v+1]; arc++ {
+			u := int(a.head[arc])
+			if h.label[u] == unlabeled && a.residual[a.reverse[arc]] > 0 {
+				h.label[u] = h.label[v] + 1
+				frontier = append(frontier, u)
+			}
+		}
+	}
+}
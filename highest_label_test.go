@@ -0,0 +1,81 @@
+package flownet_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestHighestLabelAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if _, err := graph.AddEdge(edge.from, edge.to, cap); err != nil {
+				t.Error(err)
+			}
+		}
+		graph.PushRelabelHighestLabel()
+		outflow := graph.Outflow()
+		if instance.expectedFlow != -1 && instance.expectedFlow != outflow {
+			t.Errorf("failed test %s expected max-flow of %d but was %d", path, instance.expectedFlow, outflow)
+		}
+		if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestHighestLabelAllCirculations(t *testing.T) {
+	visitAllInstances(t, CircInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewCirculation(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if edge.from == flownet.Source {
+				graph.SetNodeDemand(edge.to, -10)
+			}
+			if edge.to == flownet.Sink {
+				graph.SetNodeDemand(edge.from, 10)
+			}
+			if edge.from < 0 || edge.to < 0 || cap <= 0 {
+				continue
+			}
+			if err := graph.AddEdge(edge.from, edge.to, cap, instance.demands[edge]); err != nil {
+				t.Error(err)
+			}
+		}
+		graph.PushRelabelHighestLabel()
+		if err := flownet.SanityChecks.Circulation(graph); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestHighestLabelRandomNetworks(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for idx := 0; idx < 50; idx++ {
+		numNodes := 2 + r.Intn(40)
+		highest := flownet.NewFlowNetwork(numNodes)
+		dinic := flownet.NewFlowNetwork(numNodes)
+		for i := 0; i < 3*numNodes; i++ {
+			from, to := r.Intn(numNodes), r.Intn(numNodes)
+			if from == to {
+				continue
+			}
+			cap := r.Int63n(20)
+			highest.AddEdge(from, to, cap)
+			dinic.AddEdge(from, to, cap)
+		}
+		highest.PushRelabelHighestLabel()
+		dinic.Dinic()
+		if highest.Outflow() != dinic.Outflow() {
+			t.Errorf("test #%d: highest-label found max flow of %d but Dinic found %d", idx, highest.Outflow(), dinic.Outflow())
+		}
+		if err := flownet.SanityChecks.FlowNetwork(highest, true); err != nil {
+			t.Errorf("test #%d: sanity checks failed: %v", idx, err)
+		}
+	}
+}
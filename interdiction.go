@@ -0,0 +1,213 @@
+package flownet
+
+import (
+	"fmt"
+	"sort"
+)
+
+// InterdictOptions configures a call to Interdict.
+type InterdictOptions struct {
+	// Budget is the most which may be spent removing edges.
+	Budget int64
+	// Costs maps the ID of each edge which may be removed to the cost of removing it. If Costs is nil, every
+	// edge added via AddEdge may be removed at a cost of 1, so that Budget limits the number of edges removed.
+	Costs map[EdgeID]int64
+	// Exact, if true, finds the edges whose removal minimizes the maximum flow via branch-and-bound. This
+	// may take time exponential in the number of edges, so it is only suitable for small networks. If Exact
+	// is false, edges are chosen greedily from the minimum cuts of the network.
+	Exact bool
+}
+
+// An Interdiction is a set of edges whose removal reduces the maximum flow of a network.
+type Interdiction struct {
+	// Removed contains the IDs of the removed edges, in ascending order.
+	Removed []EdgeID
+	// Cost is the total cost of removing the edges.
+	Cost int64
+	// Flow is the maximum flow once the edges have been removed.
+	Flow int64
+}
+
+// Interdict chooses edges to remove from the network, within the provided budget, so that the maximum flow
+// once they are removed is as small as possible. When Interdict returns, the network holds its original
+// capacities and a maximum flow for them.
+//
+// The greedy heuristic repeatedly removes the edge with the most capacity per unit cost among the edges in
+// some minimum cut; removing such an edge reduces the maximum flow by exactly its capacity. The exact mode
+// starts from the greedy choice and searches for a better one. Any set of edges whose removal reduces the
+// flow must include an edge which carries flow, so only those edges are branched on, and a branch is pruned
+// when removing the edges it may still remove could not reduce the flow below the best found so far.
+//
+// An error is returned if the budget or any cost is negative, if any edge in Costs is not known, or if
+// the network cannot be solved.
+func (g *FlowNetwork) Interdict(opts InterdictOptions) (Interdiction, error) {
+	if opts.Budget < 0 {
+		return Interdiction{}, fmt.Errorf("the budget must be non-negative, found %d", opts.Budget)
+	}
+	costs := opts.Costs
+	if costs == nil {
+		costs = make(map[EdgeID]int64, len(g.edgeIDs))
+		for id := range g.edgeIDs {
+			costs[EdgeID(id)] = 1
+		}
+	}
+	in := interdictor{g: g, costs: costs, budget: opts.Budget, capacity: make(map[EdgeID]int64, len(costs))}
+	for id, cost := range costs {
+		if _, ok := g.edgeByID(id); !ok {
+			return Interdiction{}, fmt.Errorf("no edge with ID %d is known", id)
+		}
+		if cost < 0 {
+			return Interdiction{}, fmt.Errorf("removal costs must be non-negative, found %d for edge %d", cost, id)
+		}
+		in.capacity[id] = g.edgeCapacity[id]
+	}
+	defer in.restore()
+
+	g.clearFlow()
+	if err := g.resolve(); err != nil {
+		return Interdiction{}, err
+	}
+	if err := in.greedy(); err != nil {
+		return Interdiction{}, err
+	}
+	if opts.Exact {
+		in.restore()
+		in.allowed = make(map[EdgeID]bool, len(costs))
+		for id := range costs {
+			in.allowed[id] = true
+		}
+		if err := in.search(nil, 0); err != nil {
+			return Interdiction{}, err
+		}
+	}
+	sort.Slice(in.best.Removed, func(i, j int) bool { return in.best.Removed[i] < in.best.Removed[j] })
+	return in.best, nil
+}
+
+// interdictor stores the state of a search for edges to remove.
+type interdictor struct {
+	g      *FlowNetwork
+	costs  map[EdgeID]int64
+	budget int64
+	// capacity stores the original capacity of each edge which may be removed.
+	capacity map[EdgeID]int64
+	// allowed is true for each edge which may still be removed in the current branch.
+	allowed map[EdgeID]bool
+	// best stores the best interdiction found so far.
+	best Interdiction
+}
+
+// remove sets the capacity of the provided edge to zero and finds a maximum flow.
+func (in *interdictor) remove(id EdgeID) error {
+	_ = in.g.SetCapacity(id, 0)
+	return in.g.resolve()
+}
+
+// restore returns every edge which may be removed to its original capacity.
+func (in *interdictor) restore() {
+	for id, capacity := range in.capacity {
+		_ = in.g.SetCapacity(id, capacity)
+	}
+	in.g.clearFlow()
+	_ = in.g.resolve()
+}
+
+// greedy repeatedly removes the affordable edge in some minimum cut having the most capacity per unit cost,
+// and stores the result as the best interdiction.
+func (in *interdictor) greedy() error {
+	var removed []EdgeID
+	spent := int64(0)
+	for {
+		best := EdgeID(-1)
+		var bestCapacity, bestCost int64
+		for _, s := range in.g.Sensitivity() {
+			cost, ok := in.costs[s.ID]
+			if !ok || s.MarginalLoss == 0 || spent+cost > in.budget {
+				continue
+			}
+			// compare capacity per unit cost without dividing; edges which cost nothing come first.
+			better := best < 0 ||
+				float64(s.Capacity)*float64(bestCost) > float64(bestCapacity)*float64(cost) ||
+				(float64(s.Capacity)*float64(bestCost) == float64(bestCapacity)*float64(cost) && s.ID < best)
+			if better {
+				best, bestCapacity, bestCost = s.ID, s.Capacity, cost
+			}
+		}
+		if best < 0 {
+			break
+		}
+		removed = append(removed, best)
+		spent += bestCost
+		if err := in.remove(best); err != nil {
+			return err
+		}
+	}
+	in.best = Interdiction{Removed: removed, Cost: spent, Flow: in.g.Outflow()}
+	return nil
+}
+
+// interdictionOption is an edge which may be removed next, along with the flow it carries.
+type interdictionOption struct {
+	id         EdgeID
+	flow, cost int64
+}
+
+// search explores every way of removing further edges, given that the edges in removed have already been
+// removed at the provided cost and the network holds a maximum flow.
+func (in *interdictor) search(removed []EdgeID, spent int64) error {
+	flow := in.g.Outflow()
+	if flow < in.best.Flow {
+		in.best = Interdiction{Removed: append([]EdgeID{}, removed...), Cost: spent, Flow: flow}
+	}
+	var options []interdictionOption
+	for id, ok := range in.allowed {
+		cost := in.costs[id]
+		if f := in.g.FlowByID(id); ok && f > 0 && spent+cost <= in.budget {
+			options = append(options, interdictionOption{id, f, cost})
+		}
+	}
+	// options are tried in order of flow per unit cost, which also orders them for the bound below.
+	sort.Slice(options, func(i, j int) bool {
+		a, b := options[i], options[j]
+		if x, y := float64(a.flow)*float64(b.cost), float64(b.flow)*float64(a.cost); x != y {
+			return x > y
+		}
+		return a.id < b.id
+	})
+	if flow-interdictionBound(options, in.budget-spent) >= in.best.Flow {
+		return nil
+	}
+	defer func() {
+		for _, o := range options {
+			in.allowed[o.id] = true
+		}
+	}()
+	for _, o := range options {
+		// the first option removed from this node's flow is o; earlier options are never removed below here.
+		if err := in.remove(o.id); err != nil {
+			return err
+		}
+		err := in.search(append(removed, o.id), spent+o.cost)
+		_ = in.g.SetCapacity(o.id, in.capacity[o.id])
+		if err != nil {
+			return err
+		}
+		in.allowed[o.id] = false
+	}
+	return nil
+}
+
+// interdictionBound returns an upper bound on how much the flow can be reduced by removing options within
+// the provided budget, which is the most flow they can carry. options must be sorted by flow per unit cost.
+func interdictionBound(options []interdictionOption, budget int64) int64 {
+	result := int64(0)
+	for _, o := range options {
+		if budget <= 0 && o.cost > 0 {
+			break
+		}
+		// an option which does not fit within the budget is counted in full; the bound is still valid.
+		result, _ = addCapacity(result, o.flow)
+		budget -= o.cost
+	}
+	return result
+}
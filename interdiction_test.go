@@ -0,0 +1,127 @@
+package flownet_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestInterdict(t *testing.T) {
+	// the greedy heuristic removes the edge with the most capacity per unit cost, which leaves too little
+	// budget to remove a wider edge.
+	graph := flownet.NewFlowNetwork(4)
+	graph.AddEdge(flownet.Source, 0, 20)
+	a, _ := graph.AddEdge(0, 1, 5)
+	b, _ := graph.AddEdge(0, 2, 3)
+	c, _ := graph.AddEdge(0, 3, 4)
+	graph.AddEdge(1, flownet.Sink, 10)
+	graph.AddEdge(2, flownet.Sink, 10)
+	graph.AddEdge(3, flownet.Sink, 10)
+	costs := map[flownet.EdgeID]int64{a: 2, b: 1, c: 2}
+
+	result, err := graph.Interdict(flownet.InterdictOptions{Budget: 2, Costs: costs})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := flownet.Interdiction{Removed: []flownet.EdgeID{b}, Cost: 1, Flow: 9}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected greedy interdiction %+v, found %+v", expected, result)
+	}
+
+	result, err = graph.Interdict(flownet.InterdictOptions{Budget: 2, Costs: costs, Exact: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = flownet.Interdiction{Removed: []flownet.EdgeID{a}, Cost: 2, Flow: 7}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected exact interdiction %+v, found %+v", expected, result)
+	}
+	if graph.Outflow() != 12 || graph.CapacityByID(a) != 5 {
+		t.Errorf("expected the network to be restored with max flow 12, found %d", graph.Outflow())
+	}
+
+	if _, err := graph.Interdict(flownet.InterdictOptions{Budget: -1}); err == nil {
+		t.Errorf("expected an error for a negative budget")
+	}
+	if _, err := graph.Interdict(flownet.InterdictOptions{Costs: map[flownet.EdgeID]int64{100: 1}}); err == nil {
+		t.Errorf("expected an error for an unknown edge")
+	}
+	if _, err := graph.Interdict(flownet.InterdictOptions{Costs: map[flownet.EdgeID]int64{a: -1}}); err == nil {
+		t.Errorf("expected an error for a negative cost")
+	}
+}
+
+func TestInterdict_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		if len(instance.capacities) > 150 {
+			return nil
+		}
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		var edges []Edge
+		for e := range instance.capacities {
+			edges = append(edges, e)
+		}
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].from < edges[j].from || (edges[i].from == edges[j].from && edges[i].to < edges[j].to)
+		})
+		ids := make(map[Edge]flownet.EdgeID)
+		for _, e := range edges {
+			ids[e], _ = graph.AddEdge(e.from, e.to, instance.capacities[e])
+		}
+		graph.Dinic()
+		capacities := networkCapacities(graph, instance.numNodes)
+
+		// up to ten edges spread across the network may be removed.
+		var candidates []Edge
+		for i, e := range edges {
+			if e.from >= 0 && e.to >= 0 && i%(len(edges)/10+1) == 0 {
+				candidates = append(candidates, e)
+			}
+		}
+		costs := make(map[flownet.EdgeID]int64)
+		for _, e := range candidates {
+			costs[ids[e]] = int64(1 + (e.from+e.to)%3)
+		}
+		const budget = 4
+
+		// try every affordable subset of the candidates.
+		best := int64(-1)
+		for set := 0; set < 1<<uint(len(candidates)); set++ {
+			remaining := make(map[Edge]int64, len(capacities))
+			for e, c := range capacities {
+				remaining[e] = c
+			}
+			cost := int64(0)
+			for i, e := range candidates {
+				if set&(1<<uint(i)) != 0 {
+					cost += costs[ids[e]]
+					delete(remaining, e)
+				}
+			}
+			if cost > budget {
+				continue
+			}
+			if flow, _ := maxFlow(remaining, flownet.Source, flownet.Sink); best < 0 || flow < best {
+				best = flow
+			}
+		}
+
+		greedy, err := graph.Interdict(flownet.InterdictOptions{Budget: budget, Costs: costs})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		exact, err := graph.Interdict(flownet.InterdictOptions{Budget: budget, Costs: costs, Exact: true})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if exact.Flow != best {
+			t.Errorf("%s: expected exact interdiction to leave a flow of %d, found %d", path, best, exact.Flow)
+		}
+		if greedy.Flow < exact.Flow || greedy.Cost > budget || exact.Cost > budget {
+			t.Errorf("%s: inconsistent interdictions: greedy %+v, exact %+v", path, greedy, exact)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,217 @@
+package flownet
+
+import "fmt"
+
+// A MinCostFlow is a flow network in which each unit of flow along an edge has a cost. Rather than finding
+// a maximum flow, it finds the cheapest way to send a required amount of flow from the source to the sink.
+//
+// Node IDs are assigned as for a FlowNetwork, and flownet.Source and flownet.Sink refer to the source and
+// sink. Unlike a FlowNetwork, nodes are not connected to the source or sink by default; every edge leaving
+// the source or entering the sink must be added, along with its cost. The same two nodes may be joined by
+// several parallel edges, each with its own capacity and cost.
+type MinCostFlow struct {
+	// numNodes is the number of nodes in the network, other than the source and sink.
+	numNodes int
+	// edges stores each edge of the network, indexed by EdgeID.
+	edges []costEdge
+	// cost is the total cost of the flow found by the last solve.
+	cost int64
+}
+
+// costEdge is an edge having a cost per unit of flow. Nodes are referred to by internal IDs.
+type costEdge struct {
+	from, to       int
+	capacity, cost int64
+	flow           int64
+}
+
+// NewMinCostFlow constructs a new network with the provided number of nodes.
+func NewMinCostFlow(numNodes int) MinCostFlow {
+	return MinCostFlow{numNodes: numNodes}
+}
+
+// AddNode adds a new node to the network and returns its ID.
+func (m *MinCostFlow) AddNode() int {
+	m.numNodes++
+	return m.numNodes - 1
+}
+
+// AddEdge adds an edge with the provided capacity and cost per unit of flow, and returns an ID which refers
+// to it. Costs may be negative, but no cycle of edges with positive capacity may have negative total cost.
+// Attempting to use flownet.Source as toID or flownet.Sink as fromID yields an error. An error is returned
+// if either fromID or toID are not valid node IDs, or if the capacity is negative.
+func (m *MinCostFlow) AddEdge(fromID, toID int, capacity, cost int64) (EdgeID, error) {
+	if err := checkCostEdge(m.numNodes, fromID, toID, capacity); err != nil {
+		return -1, err
+	}
+	m.edges = append(m.edges, costEdge{from: internalID(fromID), to: internalID(toID), capacity: capacity, cost: cost})
+	return EdgeID(len(m.edges) - 1), nil
+}
+
+// checkCostEdge returns an error if an edge with the provided capacity cannot join the provided nodes in a
+// network with numNodes nodes.
+func checkCostEdge(numNodes, fromID, toID int, capacity int64) error {
+	if fromID == toID {
+		return fmt.Errorf("self-loops are not allowed, found one with %d -> %d", fromID, toID)
+	}
+	if fromID == Sink || toID == Source {
+		return fmt.Errorf("edges may not leave the sink or enter the source")
+	}
+	if fromID < Source || fromID >= numNodes {
+		return fmt.Errorf("no node with ID %d is known", fromID)
+	}
+	if toID < Source || toID >= numNodes {
+		return fmt.Errorf("no node with ID %d is known", toID)
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacities must be non-negative")
+	}
+	return nil
+}
+
+// Flow returns the total flow along the edges from one node to another.
+func (m MinCostFlow) Flow(from, to int) int64 {
+	result := int64(0)
+	for _, e := range m.edges {
+		if e.from == internalID(from) && e.to == internalID(to) {
+			result += e.flow
+		}
+	}
+	return result
+}
+
+// FlowByID returns the flow along the provided edge.
+func (m MinCostFlow) FlowByID(id EdgeID) int64 {
+	if id < 0 || int(id) >= len(m.edges) {
+		return 0
+	}
+	return m.edges[id].flow
+}
+
+// Outflow returns the amount of flow which leaves the network via the sink.
+func (m MinCostFlow) Outflow() int64 {
+	result := int64(0)
+	for _, e := range m.edges {
+		if e.to == sinkID {
+			result += e.flow
+		}
+	}
+	return result
+}
+
+// Cost returns the total cost of the flow found by the last call to Solve.
+func (m MinCostFlow) Cost() int64 {
+	return m.cost
+}
+
+// Solve finds the cheapest flow which sends the provided amount of flow from the source to the sink, via the
+// successive shortest path algorithm. Node potentials are first found via the Bellman-Ford algorithm, so
+// that negative costs are allowed; flow is then repeatedly sent along a cheapest path from the source to the
+// sink, found via Dijkstra's algorithm using costs reduced by the potentials.
+//
+// An error wrapping ErrInfeasible is returned if the required amount of flow cannot be sent; the flow found
+// is then the cheapest maximum flow. ErrNegativeCycle is returned if a cycle of edges with positive capacity
+// has negative total cost, in which case no flow is found. An error wrapping ErrCapacityOverflow is returned
+// if the total cost of the flow found cannot be stored in an int64; the flow is kept, but Cost returns zero.
+func (m *MinCostFlow) Solve(value int64) error {
+	if value < 0 {
+		return fmt.Errorf("the required flow must be non-negative, found %d", value)
+	}
+	c, arcs := m.compile()
+	potential, err := c.potentials()
+	if err != nil {
+		m.writeBack(newCostGraph(m.numNodes+2), nil)
+		return err
+	}
+	sent := c.successiveShortestPaths(sourceID, sinkID, value, potential)
+	if err := m.writeBack(c, arcs); err != nil {
+		return err
+	}
+	if sent < value {
+		return fmt.Errorf("%w: only %d of the %d units of flow required can be sent", ErrInfeasible, sent, value)
+	}
+	return nil
+}
+
+// compile builds the residual network of m with no flow, and returns it along with the arc for each edge.
+func (m *MinCostFlow) compile() (*costGraph, []int) {
+	c := newCostGraph(m.numNodes + 2)
+	arcs := make([]int, len(m.edges))
+	for i, e := range m.edges {
+		arcs[i] = c.addArc(e.from, e.to, e.capacity, e.cost, 0)
+	}
+	return c, arcs
+}
+
+// writeBack stores the flow along each arc of c into the edge it belongs to, and totals the cost. If arcs
+// is nil, every flow is cleared. An error wrapping ErrCapacityOverflow is returned if the total cost
+// overflows, in which case the cost is left at zero.
+func (m *MinCostFlow) writeBack(c *costGraph, arcs []int) error {
+	m.cost = 0
+	total, overflow := int64(0), false
+	for i := range m.edges {
+		m.edges[i].flow = 0
+		if arcs != nil {
+			m.edges[i].flow = c.flow(arcs[i])
+		}
+		cost, ok := mulCost(m.edges[i].flow, m.edges[i].cost)
+		if ok {
+			total, ok = addCost(total, cost)
+		}
+		overflow = overflow || !ok
+	}
+	if overflow {
+		return fmt.Errorf("%w: the total cost of the flow does not fit in an int64", ErrCapacityOverflow)
+	}
+	m.cost = total
+	return nil
+}
+
+// mulCost returns flow * cost, where flow is non-negative. Returns false if the product overflows.
+func mulCost(flow, cost int64) (int64, bool) {
+	if flow == 0 {
+		return 0, true
+	}
+	product := flow * cost
+	if product/flow != cost {
+		return 0, false
+	}
+	return product, true
+}
+
+// addCost returns x + y. Returns false if the sum overflows.
+func addCost(x, y int64) (int64, bool) {
+	sum := x + y
+	if y > 0 && sum < x || y < 0 && sum > x {
+		return 0, false
+	}
+	return sum, true
+}
+
+// successiveShortestPaths sends up to the provided amount of flow from s to t along cheapest paths, and
+// returns the amount sent. Every arc with positive residual capacity must have non-negative reduced cost
+// under the provided potentials, which are updated as flow is sent.
+func (c *costGraph) successiveShortestPaths(s, t int, value int64, potential []int64) int64 {
+	sent := int64(0)
+	for sent < value {
+		dist, parent := c.shortestPaths(s, potential)
+		if dist[t] < 0 {
+			break
+		}
+		// nodes which cannot be reached now never will be, so their potentials no longer matter.
+		for u, d := range dist {
+			if d >= 0 {
+				potential[u] += d
+			}
+		}
+		amount := value - sent
+		for v := t; v != s; v = c.head[parent[v]^1] {
+			amount = min64(amount, c.residual[parent[v]])
+		}
+		for v := t; v != s; v = c.head[parent[v]^1] {
+			c.push(parent[v], amount)
+		}
+		sent += amount
+	}
+	return sent
+}
@@ -0,0 +1,178 @@
+package flownet_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestMinCostFlow(t *testing.T) {
+	// two routes from 0 to 1: a cheap one of capacity 3 and an expensive one through 2.
+	graph := flownet.NewMinCostFlow(3)
+	graph.AddEdge(flownet.Source, 0, 10, 0)
+	cheap, _ := graph.AddEdge(0, 1, 3, 1)
+	graph.AddEdge(0, 2, 10, 2)
+	graph.AddEdge(2, 1, 10, 2)
+	graph.AddEdge(1, flownet.Sink, 10, 0)
+	if err := graph.Solve(5); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.Outflow() != 5 || graph.Cost() != 3*1+2*4 {
+		t.Errorf("expected a flow of 5 at cost 11, found a flow of %d at cost %d", graph.Outflow(), graph.Cost())
+	}
+	if graph.FlowByID(cheap) != 3 || graph.Flow(0, 2) != 2 {
+		t.Errorf("expected flows of 3 and 2, found %d and %d", graph.FlowByID(cheap), graph.Flow(0, 2))
+	}
+
+	err := graph.Solve(20)
+	if !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+	if graph.Outflow() != 10 || graph.Cost() != 3*1+7*4 {
+		t.Errorf("expected the cheapest maximum flow of 10 at cost 31, found %d at cost %d", graph.Outflow(), graph.Cost())
+	}
+}
+
+func TestMinCostFlow_ParallelAndNegative(t *testing.T) {
+	graph := flownet.NewMinCostFlow(2)
+	graph.AddEdge(flownet.Source, 0, 4, 0)
+	first, _ := graph.AddEdge(0, 1, 2, 5)
+	second, _ := graph.AddEdge(0, 1, 2, -1)
+	graph.AddEdge(1, flownet.Sink, 4, 0)
+	if err := graph.Solve(3); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.FlowByID(first) != 1 || graph.FlowByID(second) != 2 || graph.Flow(0, 1) != 3 || graph.Cost() != 3 {
+		t.Errorf("expected parallel flows of 1 and 2 at cost 3, found %d and %d at cost %d", graph.FlowByID(first), graph.FlowByID(second), graph.Cost())
+	}
+
+	graph.AddEdge(1, 0, 1, -5)
+	if err := graph.Solve(3); !errors.Is(err, flownet.ErrNegativeCycle) {
+		t.Errorf("expected ErrNegativeCycle, found %v", err)
+	}
+	if _, err := graph.AddEdge(flownet.Sink, 0, 1, 0); err == nil {
+		t.Errorf("expected an error for an edge leaving the sink")
+	}
+	if _, err := graph.AddEdge(0, 2, 1, 0); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
+
+func TestMinCostFlow_CostOverflow(t *testing.T) {
+	// each unit is cheap enough to route, but four of them cost more than an int64 can hold.
+	graph := flownet.NewMinCostFlow(1)
+	graph.AddEdge(flownet.Source, 0, 4, 0)
+	graph.AddEdge(0, flownet.Sink, 4, math.MaxInt64/3)
+	if err := graph.Solve(4); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow for an overflowing edge cost, found %v", err)
+	}
+	if graph.Outflow() != 4 || graph.Cost() != 0 {
+		t.Errorf("expected a flow of 4 with no cost, found a flow of %d at cost %d", graph.Outflow(), graph.Cost())
+	}
+
+	// no single edge overflows, but their total does.
+	graph = flownet.NewMinCostFlow(2)
+	graph.AddEdge(flownet.Source, 0, 1, math.MaxInt64/2+1)
+	graph.AddEdge(flownet.Source, 1, 1, math.MaxInt64/2+1)
+	graph.AddEdge(0, flownet.Sink, 1, 0)
+	graph.AddEdge(1, flownet.Sink, 1, 0)
+	if err := graph.Solve(2); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow for an overflowing total cost, found %v", err)
+	}
+	if err := graph.Solve(1); err != nil || graph.Cost() != math.MaxInt64/2+1 {
+		t.Errorf("expected a single unit at cost %d, found cost %d and error %v", int64(math.MaxInt64/2+1), graph.Cost(), err)
+	}
+}
+
+func TestMinCostFlow_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		// the fixtures rely on edges joining nodes to the source and sink automatically, which must be added to a
+		// MinCostFlow by hand.
+		network := flownet.NewFlowNetwork(instance.numNodes)
+		for e, c := range instance.capacities {
+			network.AddEdge(e.from, e.to, c)
+		}
+		network.Dinic()
+		capacities := networkCapacities(network, instance.numNodes)
+		maxValue, _ := maxFlow(capacities, flownet.Source, flownet.Sink)
+
+		// costs are shifted so that some are negative, unless that leaves a cycle of negative cost.
+		for _, shift := range []int64{-5, 0} {
+			graph := flownet.NewMinCostFlow(instance.numNodes)
+			var edges []costTestEdge
+			for e, c := range capacities {
+				cost := int64((31*e.from+17*e.to)%23) + shift
+				id, _ := graph.AddEdge(e.from, e.to, c, cost)
+				edges = append(edges, costTestEdge{e.from, e.to, c, cost, id})
+			}
+			for _, value := range []int64{maxValue / 2, maxValue, maxValue + 1} {
+				err := graph.Solve(value)
+				if errors.Is(err, flownet.ErrNegativeCycle) {
+					if checkMinCostFlow(instance.numNodes, edges, make([]int64, len(edges)), 0) == "" {
+						t.Errorf("%s: found ErrNegativeCycle, but no cycle has negative cost", path)
+					}
+					break
+				}
+				expected := value
+				if value > maxValue {
+					expected = maxValue
+					if !errors.Is(err, flownet.ErrInfeasible) {
+						t.Errorf("%s: expected ErrInfeasible for a flow of %d, found %v", path, value, err)
+					}
+				} else if err != nil {
+					t.Fatalf("%s: unexpected error: %v", path, err)
+				}
+				if graph.Outflow() != expected {
+					t.Errorf("%s: expected a flow of %d, found %d", path, expected, graph.Outflow())
+				}
+				flows := make([]int64, len(edges))
+				for i, e := range edges {
+					flows[i] = graph.FlowByID(e.id)
+				}
+				if err := checkMinCostFlow(instance.numNodes, edges, flows, graph.Cost()); err != "" {
+					t.Errorf("%s: flow of %d: %s", path, value, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// costTestEdge is an edge with a cost, as added to a min-cost flow problem in a test.
+type costTestEdge struct {
+	from, to       int
+	capacity, cost int64
+	id             flownet.EdgeID
+}
+
+// checkMinCostFlow checks that the provided flows respect capacities, conserve flow at every node other
+// than the source and sink, have the provided total cost, and are optimal, meaning no cycle in the residual
+// network has negative cost. Returns a description of the first problem found, or "" if there are none.
+func checkMinCostFlow(numNodes int, edges []costTestEdge, flows []int64, cost int64) string {
+	var arcs []costArc
+	balance := make(map[int]int64)
+	total := int64(0)
+	for i, e := range edges {
+		if flows[i] < 0 || flows[i] > e.capacity {
+			return "flow exceeds capacity"
+		}
+		balance[e.from] -= flows[i]
+		balance[e.to] += flows[i]
+		total += flows[i] * e.cost
+		arcs = residualArcs(arcs, e.from, e.to, flows[i], 0, e.capacity, e.cost)
+	}
+	for u := 0; u < numNodes; u++ {
+		if balance[u] != 0 {
+			return "flow is not conserved"
+		}
+	}
+	if total != cost {
+		return "cost does not match the flow"
+	}
+	if hasNegativeCycle(arcs) {
+		return "the residual network has a negative cycle, so the flow is not cheapest"
+	}
+	return ""
+}
@@ -0,0 +1,239 @@
+package flownet
+
+import "sort"
+
+// An Edge is a directed edge from the node with ID From to the node with ID To.
+type Edge struct {
+	From, To int
+}
+
+// A Cut partitions the nodes of a FlowNetwork into two sides, one containing the source and the other
+// containing the sink. The Source and Sink pseudonodes are included in SourceSide and SinkSide
+// respectively. All node IDs are sorted in ascending order.
+type Cut struct {
+	// SourceSide contains the IDs of every node on the same side of the cut as the source.
+	SourceSide []int
+	// SinkSide contains the IDs of every node on the same side of the cut as the sink.
+	SinkSide []int
+	// Edges contains every edge which leads from the source side of the cut to the sink side, sorted by
+	// From and then by To. Parallel edges appear once each.
+	Edges []Edge
+	// Nodes contains every node whose node capacity is cut, in ascending order. Flow through these nodes
+	// is limited by their capacity rather than by any edge. They are included in SourceSide.
+	Nodes []int
+	// Capacity is the total capacity of the edges and nodes crossing the cut. It is Infinite if any of them
+	// has Infinite capacity.
+	Capacity int64
+}
+
+// MinCut returns a minimum cut of the network, which is only meaningful once a maximum flow has been found.
+// The source side of the cut contains every node which can be reached from the source in the residual
+// network; every other node is on the sink side. This is the minimum cut whose source side is smallest. A
+// node with a node capacity may be cut itself, in which case it is listed in Nodes. The capacity of the cut
+// equals the value of the maximum flow.
+func (g FlowNetwork) MinCut() Cut {
+	return g.cut(g.residualReachable(sourceID))
+}
+
+// SourceMinimalCut returns the minimum cut whose source side is smallest. It is the same cut returned by
+// MinCut, and is only meaningful once a maximum flow has been found.
+func (g FlowNetwork) SourceMinimalCut() Cut {
+	return g.MinCut()
+}
+
+// SinkMinimalCut returns the minimum cut whose sink side is smallest, which is only meaningful once a
+// maximum flow has been found. The sink side of the cut contains every node from which the sink can be
+// reached in the residual network; every other node is on the source side.
+func (g FlowNetwork) SinkMinimalCut() Cut {
+	reachesSink := reachableFrom(g.residualGraph(true), sinkID)
+	sourceSide := make([]bool, len(reachesSink))
+	for u, ok := range reachesSink {
+		sourceSide[u] = !ok
+	}
+	return g.cut(sourceSide)
+}
+
+// EachMinCut calls visit once for each minimum cut of the network, stopping early if visit returns false.
+// The result is only meaningful once a maximum flow has been found; if the flow is not maximum, visit is
+// never called. The first cut visited is the source-minimal cut and the last is the sink-minimal cut.
+//
+// Minimum cuts are found from the strongly connected components of the residual network: the source side
+// of each minimum cut is a union of components which no edge with positive residual capacity leaves. There
+// may be exponentially many minimum cuts, but the time taken between visits is polynomial.
+func (g FlowNetwork) EachMinCut(visit func(Cut) bool) {
+	neighbors := g.residualGraph(false)
+	reachable := reachableFrom(neighbors, sourceID)
+	reachesSink := reachableFrom(g.residualGraph(true), sinkID)
+	if reachable[sinkID] {
+		return
+	}
+	component, count := stronglyConnectedComponents(neighbors)
+	members := make([][]int, count)
+	successors := make([][]int, count)
+	for u, vs := range neighbors {
+		members[component[u]] = append(members[component[u]], u)
+		for _, v := range vs {
+			if component[u] != component[v] {
+				successors[component[u]] = append(successors[component[u]], component[v])
+			}
+		}
+	}
+
+	// components are numbered in reverse topological order, so every successor of a component is decided
+	// before the component itself. A component may join the source side only if all its successors have.
+	included := make([]bool, count)
+	sourceSide := make([]bool, len(neighbors))
+	var enumerate func(c int) bool
+	enumerate = func(c int) bool {
+		if c == count {
+			for u := range sourceSide {
+				sourceSide[u] = included[component[u]]
+			}
+			return visit(g.cut(sourceSide))
+		}
+		u := members[c][0]
+		if reachable[u] {
+			included[c] = true
+			return enumerate(c + 1)
+		}
+		closed := !reachesSink[u]
+		for _, d := range successors[c] {
+			closed = closed && included[d]
+		}
+		included[c] = false
+		if !closed {
+			return enumerate(c + 1)
+		}
+		if !enumerate(c + 1) {
+			return false
+		}
+		included[c] = true
+		return enumerate(c + 1)
+	}
+	enumerate(0)
+}
+
+// cut returns the cut of the network whose source side contains the nodes marked in sourceSide, by internal
+// IDs.
+func (g FlowNetwork) cut(sourceSide []bool) Cut {
+	var cut Cut
+	for u := 0; u < g.numNodes+2; u++ {
+		if sourceSide[u] {
+			cut.SourceSide = append(cut.SourceSide, externalID(u))
+		} else {
+			cut.SinkSide = append(cut.SinkSide, externalID(u))
+		}
+	}
+	for e, capacity := range g.capacity {
+		if !sourceSide[e.from] || sourceSide[e.to] {
+			continue
+		}
+		if _, ok := g.inHalf[e.to]; ok {
+			cut.Nodes = append(cut.Nodes, externalID(e.from))
+		} else if visible, ok := g.visibleEdge(e); ok {
+			cut.Edges = append(cut.Edges, Edge{externalID(visible.from), externalID(visible.to)})
+			for i := 1; i < len(g.parallel[e]); i++ {
+				cut.Edges = append(cut.Edges, cut.Edges[len(cut.Edges)-1])
+			}
+		}
+		cut.Capacity, _ = addCapacity(cut.Capacity, capacity)
+	}
+	sort.Ints(cut.Nodes)
+	sortEdges(cut.Edges)
+	return cut
+}
+
+// residualReachable returns true for each node which can be reached from the provided node via edges
+// having positive residual capacity. Edges with Infinite capacity always have positive residual capacity.
+func (g FlowNetwork) residualReachable(nodeID int) []bool {
+	return reachableFrom(g.residualGraph(false), nodeID)
+}
+
+// residualGraph returns, for each node, the nodes which it joins via an edge having positive residual
+// capacity, by internal IDs. If reverse is true, the direction of every edge is reversed.
+func (g FlowNetwork) residualGraph(reverse bool) [][]int {
+	neighbors := make([][]int, g.numInternalNodes())
+	add := func(e edge) {
+		if g.capacity[e] != Infinite && g.residual(e) <= 0 {
+			return
+		}
+		if reverse {
+			e = e.reverse()
+		}
+		neighbors[e.from] = append(neighbors[e.from], e.to)
+	}
+	// residual capacity arises along edges and their reverses, so both must be considered.
+	for e := range g.capacity {
+		add(e)
+		if _, ok := g.capacity[e.reverse()]; !ok {
+			add(e.reverse())
+		}
+	}
+	return neighbors
+}
+
+// reachableFrom returns true for each node which can be reached from the provided node in a graph.
+func reachableFrom(neighbors [][]int, nodeID int) []bool {
+	reachable := make([]bool, len(neighbors))
+	reachable[nodeID] = true
+	frontier := []int{nodeID}
+	for len(frontier) > 0 {
+		u := frontier[0]
+		frontier = frontier[1:]
+		for _, v := range neighbors[u] {
+			if !reachable[v] {
+				reachable[v] = true
+				frontier = append(frontier, v)
+			}
+		}
+	}
+	return reachable
+}
+
+// stronglyConnectedComponents labels each node of a graph with its strongly connected component, using
+// Tarjan's algorithm, and returns the labels along with the number of components. Components are numbered
+// in reverse topological order; every edge between two components leads to the one with the lower label.
+func stronglyConnectedComponents(neighbors [][]int) ([]int, int) {
+	n := len(neighbors)
+	component, index, lowLink := make([]int, n), make([]int, n), make([]int, n)
+	for u := range index {
+		index[u] = -1
+	}
+	var stack []int
+	onStack := make([]bool, n)
+	next, count := 0, 0
+	var visit func(u int)
+	visit = func(u int) {
+		index[u], lowLink[u] = next, next
+		next++
+		stack = append(stack, u)
+		onStack[u] = true
+		for _, v := range neighbors[u] {
+			if index[v] < 0 {
+				visit(v)
+				lowLink[u] = min(lowLink[u], lowLink[v])
+			} else if onStack[v] {
+				lowLink[u] = min(lowLink[u], index[v])
+			}
+		}
+		if lowLink[u] != index[u] {
+			return
+		}
+		for {
+			v := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[v] = false
+			component[v] = count
+			if v == u {
+				break
+			}
+		}
+		count++
+	}
+	for u := range neighbors {
+		if index[u] < 0 {
+			visit(u)
+		}
+	}
+	return component, count
+}
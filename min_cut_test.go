@@ -0,0 +1,223 @@
+package flownet_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestMinCutAllFlowNetworks(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			graph.AddEdge(edge.from, edge.to, cap)
+		}
+		graph.Dinic()
+		cut := graph.MinCut()
+		if cut.Capacity != graph.Outflow() {
+			t.Errorf("failed test %s: cut capacity %d does not equal max flow %d", path, cut.Capacity, graph.Outflow())
+		}
+		if len(cut.SourceSide)+len(cut.SinkSide) != instance.numNodes+2 {
+			t.Errorf("failed test %s: cut does not partition all %d nodes", path, instance.numNodes+2)
+		}
+		if cut.SourceSide[0] != flownet.Source || cut.SinkSide[0] != flownet.Sink {
+			t.Errorf("failed test %s: expected source and sink to be on opposite sides of the cut", path)
+		}
+		for _, e := range cut.Edges {
+			if graph.Residual(e.From, e.To) != 0 {
+				t.Errorf("failed test %s: cut edge from %d to %d is not saturated", path, e.From, e.To)
+			}
+		}
+		return nil
+	})
+}
+
+func TestMinCut(t *testing.T) {
+	graph := flownet.NewFlowNetwork(4)
+	graph.AddEdge(flownet.Source, 0, 10)
+	graph.AddEdge(0, 1, 3)
+	graph.AddEdge(0, 2, 8)
+	graph.AddEdge(2, 1, 1)
+	graph.AddEdge(1, 3, 10)
+	graph.AddEdge(3, flownet.Sink, 10)
+	graph.AddEdge(2, flownet.Sink, 2)
+	graph.PushRelabel()
+
+	cut := graph.MinCut()
+	expected := flownet.Cut{
+		SourceSide: []int{flownet.Source, 0, 2},
+		SinkSide:   []int{flownet.Sink, 1, 3},
+		Edges:      []flownet.Edge{{From: 0, To: 1}, {From: 2, To: flownet.Sink}, {From: 2, To: 1}},
+		Capacity:   6,
+	}
+	if !reflect.DeepEqual(cut, expected) {
+		t.Errorf("expected cut %+v, found %+v", expected, cut)
+	}
+}
+
+func TestMinCut_DefaultSourceSink(t *testing.T) {
+	graph := flownet.NewFlowNetwork(2)
+	graph.AddEdge(0, 1, 5)
+	graph.Dinic()
+
+	cut := graph.MinCut()
+	if cut.Capacity != 5 {
+		t.Errorf("expected cut capacity of 5, found %d", cut.Capacity)
+	}
+	if !reflect.DeepEqual(cut.Edges, []flownet.Edge{{From: flownet.Source, To: 0}}) &&
+		!reflect.DeepEqual(cut.Edges, []flownet.Edge{{From: 0, To: 1}}) {
+		t.Errorf("unexpected cut edges %v", cut.Edges)
+	}
+}
+
+func TestSourceAndSinkMinimalCuts(t *testing.T) {
+	// a chain in which every edge is a minimum cut.
+	graph := flownet.NewFlowNetwork(2)
+	graph.AddEdge(flownet.Source, 0, 5)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(1, flownet.Sink, 5)
+	graph.PushRelabel()
+
+	if cut := graph.SourceMinimalCut(); !reflect.DeepEqual(cut.SourceSide, []int{flownet.Source}) {
+		t.Errorf("expected the source-minimal cut to contain only the source, found %v", cut.SourceSide)
+	}
+	cut := graph.SinkMinimalCut()
+	if !reflect.DeepEqual(cut.SinkSide, []int{flownet.Sink}) || cut.Capacity != 5 {
+		t.Errorf("expected the sink-minimal cut to contain only the sink, found %+v", cut)
+	}
+	if !reflect.DeepEqual(cut.Edges, []flownet.Edge{{From: 1, To: flownet.Sink}}) {
+		t.Errorf("expected the sink-minimal cut to cut the edge into the sink, found %v", cut.Edges)
+	}
+
+	var sides [][]int
+	graph.EachMinCut(func(cut flownet.Cut) bool {
+		if cut.Capacity != 5 {
+			t.Errorf("expected every cut to have capacity 5, found %+v", cut)
+		}
+		sides = append(sides, cut.SourceSide)
+		return true
+	})
+	expected := [][]int{{flownet.Source}, {flownet.Source, 0}, {flownet.Source, 0, 1}}
+	if !reflect.DeepEqual(sides, expected) {
+		t.Errorf("expected source sides %v, found %v", expected, sides)
+	}
+
+	visits := 0
+	graph.EachMinCut(func(flownet.Cut) bool {
+		visits++
+		return false
+	})
+	if visits != 1 {
+		t.Errorf("expected enumeration to stop after 1 cut, found %d", visits)
+	}
+}
+
+func TestMinimalCuts_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewFlowNetwork(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			graph.AddEdge(edge.from, edge.to, cap)
+		}
+		graph.Dinic()
+		capacities := networkCapacities(graph, instance.numNodes)
+		flow, residual := maxFlow(capacities, flownet.Source, flownet.Sink)
+
+		// in the residual network of any maximum flow, the nodes reachable from the source form the source side of
+		// the source-minimal cut, and the nodes which can reach the sink form the sink side of the sink-minimal cut.
+		reversed := make(map[Edge]int64, len(residual))
+		for e, r := range residual {
+			reversed[Edge{e.to, e.from}] = r
+		}
+		fromSource, toSink := reachable(residual, flownet.Source), reachable(reversed, flownet.Sink)
+		var sourceSide, sinkSide []int
+		for u := flownet.Source; u < instance.numNodes; u++ {
+			if fromSource[u] {
+				sourceSide = append(sourceSide, u)
+			}
+			if toSink[u] {
+				sinkSide = append(sinkSide, u)
+			}
+		}
+		if cut := graph.SourceMinimalCut(); !reflect.DeepEqual(cut.SourceSide, sourceSide) || cut.Capacity != flow {
+			t.Errorf("%s: expected source side %v with capacity %d, found %v with capacity %d", path,
+				sourceSide, flow, cut.SourceSide, cut.Capacity)
+		}
+		if cut := graph.SinkMinimalCut(); !reflect.DeepEqual(cut.SinkSide, sinkSide) || cut.Capacity != flow {
+			t.Errorf("%s: expected sink side %v with capacity %d, found %v with capacity %d", path,
+				sinkSide, flow, cut.SinkSide, cut.Capacity)
+		}
+		return nil
+	})
+}
+
+func TestEachMinCut_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		n := instance.numNodes
+		if n > 12 {
+			return nil
+		}
+		// unit capacities give the fixtures other minimum cuts to find.
+		for _, unit := range []bool{false, true} {
+			graph := flownet.NewFlowNetwork(n)
+			for edge, cap := range instance.capacities {
+				if unit {
+					cap = 1
+				}
+				graph.AddEdge(edge.from, edge.to, cap)
+			}
+			graph.Dinic()
+			checkEachMinCut(t, path, graph, n)
+		}
+		return nil
+	})
+}
+
+// checkEachMinCut reports an error unless EachMinCut visits each minimum cut of the provided network exactly
+// once, as found by trying every cut.
+func checkEachMinCut(t *testing.T, path string, graph flownet.FlowNetwork, n int) {
+	t.Helper()
+	capacities := networkCapacities(graph, n)
+	flow, _ := maxFlow(capacities, flownet.Source, flownet.Sink)
+	cutCapacity := func(inSource func(int) bool) int64 {
+		result := int64(0)
+		for e, c := range capacities {
+			if inSource(e.from) && !inSource(e.to) {
+				result += c
+			}
+		}
+		return result
+	}
+
+	found := make(map[string]bool)
+	graph.EachMinCut(func(cut flownet.Cut) bool {
+		key := fmt.Sprint(cut.SourceSide)
+		if found[key] {
+			t.Errorf("%s: cut with source side %v visited twice", path, cut.SourceSide)
+		}
+		found[key] = true
+		inSource := make(map[int]bool)
+		for _, u := range cut.SourceSide {
+			inSource[u] = true
+		}
+		if c := cutCapacity(func(u int) bool { return inSource[u] }); cut.Capacity != flow || c != flow {
+			t.Errorf("%s: expected cut capacity %d, found %d, reported as %d", path, flow, c, cut.Capacity)
+		}
+		return true
+	})
+
+	// every set of nodes is the source side of a cut, once the source is added to it.
+	expected := 0
+	for set := 0; set < 1<<uint(n); set++ {
+		inSource := func(u int) bool {
+			return u == flownet.Source || (u >= 0 && set&(1<<uint(u)) != 0)
+		}
+		if cutCapacity(inSource) == flow {
+			expected++
+		}
+	}
+	if len(found) != expected {
+		t.Errorf("%s: expected %d minimum cuts, found %d", path, expected, len(found))
+	}
+}
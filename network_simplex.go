@@ -0,0 +1,662 @@
+package flownet
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// A SimplexBasis is the spanning tree basis of the circulation found by NetworkSimplex. The sink serves as
+// the root of the tree, and every node is joined to it by a pair of artificial edges, one in each
+// direction, which carry no flow in a valid circulation. Every edge outside the tree is either empty or
+// saturated; the flow along the edges of the tree follows from the flow along the others.
+//
+// Edges from Source meet supplies. The flow which meets edge and node demands is fixed, so it is left out of
+// the basis. The edge which limits the flow through a node with a node capacity is listed as an edge from the
+// node to itself.
+type SimplexBasis struct {
+	// Tree contains every edge of the spanning tree, including artificial edges, which lead from a node to
+	// Sink or from Sink to a node. Edges are sorted by From and then by To.
+	Tree []Edge
+	// Saturated contains every edge outside the spanning tree whose flow equals its capacity, sorted by From
+	// and then by To.
+	Saturated []Edge
+}
+
+// simplexState is the state of an arc in a spanning tree basis.
+type simplexState int8
+
+const (
+	// simplexLower marks an arc outside the tree which carries no flow.
+	simplexLower simplexState = iota
+	// simplexTree marks an arc of the tree.
+	simplexTree
+	// simplexUpper marks an arc outside the tree which is saturated.
+	simplexUpper
+)
+
+// NetworkSimplex finds the cheapest valid circulation, using the costs set via SetEdgeCost, via the primal
+// network simplex algorithm. Edge and node demands are met just as by CostScaling, which finds a circulation
+// of the same cost.
+//
+// The solve keeps a spanning tree basis, in which each edge outside the tree is empty or saturated, along
+// with node potentials under which every edge of the tree has zero reduced cost. Each pivot brings into the
+// tree an edge whose reduced cost shows that the circulation can be made cheaper, and removes the last edge
+// to block the flow sent around the resulting cycle, which keeps the tree strongly feasible so that no
+// sequence of degenerate pivots repeats. Nodes start out joined to the root by artificial edges whose cost
+// exceeds that of any path.
+//
+// Each solve starts from the basis found by the last one, or set via SetBasis, so only a few pivots are
+// needed after a small change to the circulation. Any part of the basis which no longer fits, such as a tree
+// edge whose flow would now exceed its capacity, is replaced by artificial edges. The basis and potentials
+// are available via Basis and Potential.
+//
+// Errors are returned as for CostScaling, and the flow is kept or cleared just as it is there. The basis is
+// kept whatever error is returned.
+func (c *Circulation) NetworkSimplex() error {
+	c.clearFlow()
+	withDemands := c.hasDemands()
+	if withDemands {
+		if err := c.connectDemands(); err != nil {
+			return err
+		}
+		if err := c.prepare(); err != nil {
+			return err
+		}
+	}
+	model, err := c.costModel()
+	if err != nil {
+		return err
+	}
+	// the flow along edges joined to the source or sink is fixed, so it is supplied to and demanded from the
+	// nodes at their other ends.
+	supply := make([]int64, model.numNodes())
+	fixed := c.fixedNodes()
+	if withDemands {
+		for e, capacity := range c.FlowNetwork.capacity {
+			if e.to == sinkID && !fixed[e.from] {
+				supply[e.from] -= capacity
+			}
+			if e.from == sourceID && !fixed[e.to] {
+				supply[e.to] += capacity
+			}
+		}
+	}
+
+	s := newNetworkSimplex(model, supply, sinkID)
+	s.start(c.basis)
+	for arc := s.entering(); arc >= 0; arc = s.entering() {
+		s.pivot(arc)
+	}
+	c.basis = s.basis()
+	c.potential = s.potential
+	if unmet := s.artificialFlow(); unmet > 0 {
+		c.clearFlow()
+		return fmt.Errorf("%w: %d units of demand cannot be met", ErrInfeasible, unmet)
+	}
+	for i := range model.edges {
+		model.push(2*i, s.flow[i])
+	}
+	model.writeBack(c)
+	if withDemands {
+		for e, capacity := range c.FlowNetwork.capacity {
+			if (e.to == sinkID && !fixed[e.from]) || (e.from == sourceID && !fixed[e.to]) {
+				c.preflow[e] = capacity
+			}
+		}
+	}
+	if _, ok := c.totalCost(); !ok {
+		return fmt.Errorf("%w: the total cost of the circulation does not fit in an int64", ErrCapacityOverflow)
+	}
+	return nil
+}
+
+// Basis returns the spanning tree basis found by the last call to NetworkSimplex, or an empty basis if it
+// has not been called.
+func (c *Circulation) Basis() SimplexBasis {
+	var basis SimplexBasis
+	fixed := c.fixedNodes()
+	delete(fixed, sinkID)
+	for e, state := range c.basis {
+		result := Edge{c.basisNode(e.from), c.basisNode(e.to)}
+		if e.from == sinkID || e.to == sinkID {
+			// the artificial edges of nodes left out of the model are left out.
+			if fixed[e.from] || fixed[e.to] || c.isHidden(e.from) || c.isHidden(e.to) {
+				continue
+			}
+		} else if u, ok := c.inHalf[e.to]; ok && u == e.from {
+			result.To = result.From
+		} else if visible, ok := c.visibleEdge(e); ok {
+			result = Edge{c.basisNode(visible.from), c.basisNode(visible.to)}
+		}
+		if state == simplexTree {
+			basis.Tree = append(basis.Tree, result)
+		} else {
+			basis.Saturated = append(basis.Saturated, result)
+		}
+	}
+	sortEdges(basis.Tree)
+	sortEdges(basis.Saturated)
+	return basis
+}
+
+// basisNode returns the external ID by which the node with the provided internal ID appears in a basis. The
+// special source node which meets supplies appears as Source.
+func (c *Circulation) basisNode(u int) int {
+	if c.nodeSource != 0 && u == internalID(c.nodeSource) {
+		return Source
+	}
+	return externalID(u)
+}
+
+// SetBasis sets the basis from which the next call to NetworkSimplex starts, such as one found via Basis for
+// another circulation with the same nodes. Edges which do not belong to the circulation are ignored, and
+// any part of the basis which does not fit is replaced just as when a solve starts from the basis found by
+// the last one. An error is returned if an edge joins an unknown node, or if an edge from a node to itself
+// belongs to a node without a node capacity.
+func (c *Circulation) SetBasis(basis SimplexBasis) error {
+	result := make(map[edge]simplexState, len(basis.Tree)+len(basis.Saturated))
+	add := func(e Edge, state simplexState) error {
+		for _, nodeID := range []int{e.From, e.To} {
+			if nodeID < Source || nodeID >= c.numNodes {
+				return fmt.Errorf("no node with id %d is known", nodeID)
+			}
+		}
+		key := edge{internalID(c.outHalfID(e.From)), internalID(e.To)}
+		if e.From == Sink || e.To == Sink {
+			key = edge{internalID(e.From), internalID(e.To)}
+		} else if e.From == e.To {
+			h, ok := c.outHalf[internalID(e.From)]
+			if !ok {
+				return fmt.Errorf("node %d has no node capacity", e.From)
+			}
+			key = edge{internalID(e.From), h}
+		}
+		if c.nodeSource != 0 {
+			// supplies are met via the special source node.
+			if key.from == sourceID {
+				key.from = internalID(c.nodeSource)
+			}
+			if key.to == sourceID {
+				key.to = internalID(c.nodeSource)
+			}
+		}
+		result[key] = state
+		return nil
+	}
+	for _, e := range basis.Tree {
+		if err := add(e, simplexTree); err != nil {
+			return err
+		}
+	}
+	for _, e := range basis.Saturated {
+		if err := add(e, simplexUpper); err != nil {
+			return err
+		}
+	}
+	c.basis = result
+	return nil
+}
+
+// Potential returns the potential of the provided node in the basis found by the last call to
+// NetworkSimplex. The potential of Sink, the root of the spanning tree, is zero, and the potential of the
+// head of each edge in the tree is that of its tail plus the edge's cost. In the cheapest circulation, every
+// empty edge has a cost at least the potential of its head less that of its tail, and every saturated edge
+// has a cost at most that difference. Nodes joined to the rest of the tree only through the root have
+// potentials which include the large cost of an artificial edge. The potential of Source is that of the node
+// which meets supplies. Returns 0 if NetworkSimplex has not been called, or if the node is unknown.
+func (c *Circulation) Potential(nodeID int) int64 {
+	u := internalID(nodeID)
+	if nodeID == Source && c.nodeSource != 0 {
+		u = internalID(c.nodeSource)
+	}
+	if u < 0 || u >= len(c.potential) {
+		return 0
+	}
+	return c.potential[u]
+}
+
+// networkSimplex stores the state of the network simplex algorithm. Arcs are the edges of a
+// circulationModel, by index, followed by a pair of artificial arcs for each node u: arc m+2u leads from u
+// to the root, and arc m+2u+1 leads from the root to u, where m is the number of edges.
+type networkSimplex struct {
+	model circulationModel
+	root  int
+	// tail, head, capacity, cost, flow, and state describe each arc.
+	tail, head           []int
+	capacity, cost, flow []int64
+	state                []simplexState
+	// supply stores the amount by which the flow leaving each node must exceed the flow entering it.
+	supply []int64
+	// parent and pred store the parent of each node in the tree and the arc joining them, or -1 for the
+	// root. depth stores the number of arcs between each node and the root.
+	parent, pred, depth []int
+	// thread stores the node after each in a preorder walk of the tree, which returns to the root after the
+	// last node, and prevThread stores the node before each. The subtree of a node is the node itself
+	// followed by the nodes after it in the walk which are deeper than it.
+	thread, prevThread []int
+	// potential stores the potential of each node.
+	potential []int64
+	// next stores the arc at which the search for an entering arc resumes.
+	next int
+}
+
+// newNetworkSimplex prepares to find the cheapest circulation in the provided model, whose flow is ignored,
+// which meets the provided supplies.
+func newNetworkSimplex(model circulationModel, supply []int64, root int) *networkSimplex {
+	n := model.numNodes()
+	s := &networkSimplex{
+		model:      model,
+		root:       root,
+		supply:     supply,
+		parent:     make([]int, n),
+		pred:       make([]int, n),
+		depth:      make([]int, n),
+		thread:     make([]int, n),
+		prevThread: make([]int, n),
+		potential:  make([]int64, n),
+	}
+	// the cost of an artificial arc must exceed that of any path, so that no artificial arc carries flow
+	// unless no valid circulation exists.
+	artificialCost := int64(1)
+	for i := range model.edges {
+		arc := 2 * i
+		s.tail = append(s.tail, model.head[arc^1])
+		s.head = append(s.head, model.head[arc])
+		s.capacity = append(s.capacity, model.residual[arc]+model.residual[arc^1])
+		s.cost = append(s.cost, model.cost[arc])
+		if cost := model.cost[arc]; cost < 0 {
+			artificialCost -= cost
+		} else {
+			artificialCost += cost
+		}
+	}
+	for u := 0; u < n; u++ {
+		s.tail = append(s.tail, u, root)
+		s.head = append(s.head, root, u)
+		s.capacity = append(s.capacity, Infinite, Infinite)
+		s.cost = append(s.cost, artificialCost, artificialCost)
+	}
+	s.flow = make([]int64, len(s.tail))
+	s.state = make([]simplexState, len(s.tail))
+	return s
+}
+
+// key returns the edge by which the state of an arc is stored in a basis, by internal IDs.
+func (s *networkSimplex) key(arc int) edge {
+	if arc < len(s.model.edges) {
+		return s.model.edges[arc]
+	}
+	return edge{s.tail[arc], s.head[arc]}
+}
+
+// basis returns the state of every arc which is not empty, keyed by the edge of the arc.
+func (s *networkSimplex) basis() map[edge]simplexState {
+	result := make(map[edge]simplexState)
+	for arc, state := range s.state {
+		if state != simplexLower && s.tail[arc] != s.head[arc] {
+			result[s.key(arc)] = state
+		}
+	}
+	return result
+}
+
+// artificialFlow returns the total flow along the artificial arcs leading from the root.
+func (s *networkSimplex) artificialFlow() int64 {
+	total := int64(0)
+	for arc := len(s.model.edges) + 1; arc < len(s.flow); arc += 2 {
+		total += s.flow[arc]
+	}
+	return total
+}
+
+// start builds a strongly feasible spanning tree from the provided basis, which may be nil. Tree arcs of
+// the basis which would form a cycle, or whose flow would be out of bounds, are left out of the tree, and
+// every node which is then cut off from the root is joined to it by an artificial arc.
+func (s *networkSimplex) start(basis map[edge]simplexState) {
+	n := len(s.parent)
+	component := make([]int, n)
+	for u := range component {
+		component[u] = u
+	}
+	var find func(u int) int
+	find = func(u int) int {
+		if component[u] != u {
+			component[u] = find(component[u])
+		}
+		return component[u]
+	}
+	adjacent := make([][]int, n)
+	for arc := range s.state {
+		s.state[arc], s.flow[arc] = basis[s.key(arc)], 0
+		if s.state[arc] == simplexUpper {
+			if s.capacity[arc] == Infinite {
+				s.state[arc] = simplexLower
+			} else {
+				s.flow[arc] = s.capacity[arc]
+			}
+		}
+		if s.state[arc] != simplexTree {
+			continue
+		}
+		u, v := find(s.tail[arc]), find(s.head[arc])
+		if u == v {
+			s.state[arc] = simplexLower
+			continue
+		}
+		component[u] = v
+		adjacent[s.tail[arc]] = append(adjacent[s.tail[arc]], arc)
+		adjacent[s.head[arc]] = append(adjacent[s.head[arc]], arc)
+	}
+
+	// order the nodes so that each comes after its parent, starting from the root and then from each node
+	// cut off from it, which is joined to the root later.
+	order := make([]int, 0, n)
+	visited := make([]bool, n)
+	visit := func(start int) {
+		visited[start], s.parent[start], s.pred[start] = true, -1, -1
+		order = append(order, start)
+		for i := len(order) - 1; i < len(order); i++ {
+			u := order[i]
+			for _, arc := range adjacent[u] {
+				v := s.tail[arc] + s.head[arc] - u
+				if !visited[v] {
+					visited[v], s.parent[v], s.pred[v] = true, u, arc
+					order = append(order, v)
+				}
+			}
+		}
+	}
+	visit(s.root)
+	for u := range visited {
+		if !visited[u] {
+			visit(u)
+		}
+	}
+
+	// each node must send the excess of its supply over the flow it sends along arcs outside the tree to its
+	// parent; the leaves are settled first.
+	excess := append([]int64{}, s.supply...)
+	for arc, flow := range s.flow {
+		excess[s.tail[arc]] -= flow
+		excess[s.head[arc]] += flow
+	}
+	for i := len(order) - 1; i >= 0; i-- {
+		u := order[i]
+		if u == s.root {
+			continue
+		}
+		if arc := s.pred[u]; arc >= 0 {
+			up := s.tail[arc] == u
+			flow := excess[u]
+			if !up {
+				flow = -flow
+			}
+			// strong feasibility requires that flow can be sent from u towards the root.
+			if up && flow >= 0 && flow < s.capacity[arc] || !up && flow > 0 && flow <= s.capacity[arc] {
+				s.flow[arc] = flow
+				excess[s.parent[u]] += excess[u]
+				continue
+			}
+			// the arc leaves the tree at whichever bound is nearer.
+			s.state[arc], s.flow[arc] = simplexLower, 0
+			if flow > 0 {
+				s.state[arc], s.flow[arc] = simplexUpper, s.capacity[arc]
+			}
+			excess[s.tail[arc]] -= s.flow[arc]
+			excess[s.head[arc]] += s.flow[arc]
+		}
+		arc := len(s.model.edges) + 2*u
+		if excess[u] < 0 {
+			arc++
+		}
+		s.state[arc] = simplexTree
+		s.flow[arc] = excess[u]
+		if excess[u] < 0 {
+			s.flow[arc] = -excess[u]
+		}
+		excess[s.root] += excess[u]
+	}
+
+	s.rebuild()
+}
+
+// rebuild finds the parent, depth, and potential of every node from the arcs of the tree, along with the
+// thread.
+func (s *networkSimplex) rebuild() {
+	incident := make([][]int, len(s.parent))
+	for arc, state := range s.state {
+		if state == simplexTree {
+			incident[s.tail[arc]] = append(incident[s.tail[arc]], arc)
+			incident[s.head[arc]] = append(incident[s.head[arc]], arc)
+		}
+	}
+	s.parent[s.root], s.pred[s.root], s.depth[s.root], s.potential[s.root] = -1, -1, 0, 0
+	frontier := []int{s.root}
+	last := -1
+	for len(frontier) > 0 {
+		u := frontier[len(frontier)-1]
+		frontier = frontier[:len(frontier)-1]
+		if last >= 0 {
+			s.thread[last], s.prevThread[u] = u, last
+		}
+		last = u
+		for _, arc := range incident[u] {
+			if arc == s.pred[u] {
+				continue
+			}
+			v := s.tail[arc] + s.head[arc] - u
+			s.parent[v], s.pred[v], s.depth[v] = u, arc, s.depth[u]+1
+			if s.tail[arc] == u {
+				s.potential[v] = s.potential[u] + s.cost[arc]
+			} else {
+				s.potential[v] = s.potential[u] - s.cost[arc]
+			}
+			frontier = append(frontier, v)
+		}
+	}
+	s.thread[last], s.prevThread[s.root] = s.root, last
+}
+
+// reducedCost returns the cost of an arc adjusted by the potentials of its ends.
+func (s *networkSimplex) reducedCost(arc int) int64 {
+	return s.cost[arc] + s.potential[s.tail[arc]] - s.potential[s.head[arc]]
+}
+
+// entering returns an arc outside the tree along which flow can be changed to reduce the cost, or -1 if
+// there is none. Arcs are searched in blocks, and the arc which most reduces the cost in the first block
+// containing any is chosen.
+func (s *networkSimplex) entering() int {
+	numArcs := len(s.state)
+	blockSize := int(math.Sqrt(float64(numArcs)))
+	if blockSize < 10 {
+		blockSize = 10
+	}
+	best, bestArc := int64(0), -1
+	for i := 0; i < numArcs; i++ {
+		arc := (s.next + i) % numArcs
+		violation := int64(0)
+		switch s.state[arc] {
+		case simplexLower:
+			violation = s.reducedCost(arc)
+		case simplexUpper:
+			violation = -s.reducedCost(arc)
+		}
+		if violation < best {
+			best, bestArc = violation, arc
+		}
+		if (i+1)%blockSize == 0 && bestArc >= 0 {
+			s.next = (arc + 1) % numArcs
+			return bestArc
+		}
+	}
+	s.next = 0
+	return bestArc
+}
+
+// pivot sends as much flow as possible around the cycle which the entering arc forms with the tree, then
+// replaces the last arc of the cycle to block the flow with the entering arc.
+func (s *networkSimplex) pivot(entering int) {
+	// flow is sent along the entering arc from first to second, then up the tree to the node where the
+	// paths from first and second to the root join, and then down the tree back to first.
+	first, second := s.tail[entering], s.head[entering]
+	if s.state[entering] == simplexUpper {
+		first, second = second, first
+	}
+	join := s.join(first, second)
+
+	delta, leaving, moved := s.capacity[entering], -1, -1
+	// the last arc to block the flow, following the cycle from the join, is chosen; ties on the way down go
+	// to the arc nearest first, and ties on the way up to the arc nearest the join.
+	for u := first; u != join; u = s.parent[u] {
+		if residual := s.residual(s.pred[u], s.head[s.pred[u]] == u); residual < delta {
+			delta, leaving, moved = residual, u, first
+		}
+	}
+	for u := second; u != join; u = s.parent[u] {
+		if residual := s.residual(s.pred[u], s.tail[s.pred[u]] == u); residual <= delta {
+			delta, leaving, moved = residual, u, second
+		}
+	}
+
+	if delta > 0 {
+		if s.state[entering] == simplexUpper {
+			s.flow[entering] -= delta
+		} else {
+			s.flow[entering] += delta
+		}
+		for u := first; u != join; u = s.parent[u] {
+			s.send(s.pred[u], s.head[s.pred[u]] == u, delta)
+		}
+		for u := second; u != join; u = s.parent[u] {
+			s.send(s.pred[u], s.tail[s.pred[u]] == u, delta)
+		}
+	}
+
+	if leaving < 0 {
+		// the entering arc blocks the flow itself, so it moves from one bound to the other.
+		if s.state[entering] == simplexUpper {
+			s.state[entering] = simplexLower
+		} else {
+			s.state[entering] = simplexUpper
+		}
+		return
+	}
+	arc := s.pred[leaving]
+	s.state[arc] = simplexUpper
+	if s.flow[arc] == 0 {
+		s.state[arc] = simplexLower
+	}
+	s.state[entering] = simplexTree
+	s.update(entering, moved, leaving)
+}
+
+// update reshapes the tree once the entering arc replaces the arc joining the node q to its parent. The
+// subtree of q is cut off, rerooted at a, the end of the entering arc within it, and hung from the other end
+// of the entering arc. Only the nodes of the subtree change their parent, depth, potential, or place in the
+// thread.
+func (s *networkSimplex) update(entering, a, q int) {
+	b := s.tail[entering] + s.head[entering] - a
+	subtree := []int{q}
+	for u := s.thread[q]; s.depth[u] > s.depth[q]; u = s.thread[u] {
+		subtree = append(subtree, u)
+	}
+	path := []int{a}
+	for u := a; u != q; u = s.parent[u] {
+		path = append(path, s.parent[u])
+	}
+
+	// the subtree of each node on the path from a to q takes up the indices from start to end in the walk
+	// of the subtree of q, and contains the subtree of the node before it on the path.
+	onPath := make(map[int]int, len(path))
+	for j, u := range path {
+		onPath[u] = j
+	}
+	start, end := make([]int, len(path)), make([]int, len(path))
+	for i, u := range subtree {
+		if j, ok := onPath[u]; ok {
+			start[j] = i
+		}
+	}
+	i := start[0] + 1
+	for j, u := range path {
+		for i < len(subtree) && s.depth[subtree[i]] > s.depth[u] {
+			i++
+		}
+		end[j] = i
+	}
+	// once rerooted, the walk visits the subtree of a, then each node further along the path followed by the
+	// rest of its old subtree.
+	order := append(make([]int, 0, len(subtree)), subtree[start[0]:end[0]]...)
+	for j := 1; j < len(path); j++ {
+		order = append(order, subtree[start[j]:start[j-1]]...)
+		order = append(order, subtree[end[j-1]:end[j]]...)
+	}
+
+	for j := len(path) - 1; j > 0; j-- {
+		s.parent[path[j]], s.pred[path[j]] = path[j-1], s.pred[path[j-1]]
+	}
+	s.parent[a], s.pred[a] = b, entering
+
+	// the subtree is cut out of the thread and spliced back in just after b.
+	before, after := s.prevThread[q], s.thread[subtree[len(subtree)-1]]
+	s.thread[before], s.prevThread[after] = after, before
+	last, after := b, s.thread[b]
+	for _, u := range order {
+		s.thread[last], s.prevThread[u] = u, last
+		last = u
+	}
+	s.thread[last], s.prevThread[after] = after, last
+
+	// every potential in the subtree shifts by the amount which gives the entering arc zero reduced cost.
+	shift := s.potential[b] - s.cost[entering] - s.potential[a]
+	if s.head[entering] == a {
+		shift = s.potential[b] + s.cost[entering] - s.potential[a]
+	}
+	for _, u := range order {
+		s.depth[u] = s.depth[s.parent[u]] + 1
+		s.potential[u] += shift
+	}
+}
+
+// join returns the node at which the paths from u and v to the root meet.
+func (s *networkSimplex) join(u, v int) int {
+	for u != v {
+		if s.depth[u] >= s.depth[v] {
+			u = s.parent[u]
+		} else {
+			v = s.parent[v]
+		}
+	}
+	return u
+}
+
+// residual returns the amount of flow which may be sent along an arc, if forward is true, or against it.
+func (s *networkSimplex) residual(arc int, forward bool) int64 {
+	if !forward {
+		return s.flow[arc]
+	}
+	if s.capacity[arc] == Infinite {
+		return Infinite
+	}
+	return s.capacity[arc] - s.flow[arc]
+}
+
+// send sends the provided amount of flow along an arc, if forward is true, or against it.
+func (s *networkSimplex) send(arc int, forward bool, amount int64) {
+	if forward {
+		s.flow[arc] += amount
+	} else {
+		s.flow[arc] -= amount
+	}
+}
+
+// sortEdges sorts edges by From and then by To.
+func sortEdges(edges []Edge) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+		return edges[i].To < edges[j].To
+	})
+}
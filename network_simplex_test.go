@@ -0,0 +1,282 @@
+package flownet_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestNetworkSimplex(t *testing.T) {
+	// node 0 supplies 5 units to node 3, via a cheap route of capacity 3 or an expensive one.
+	c := flownet.NewCirculation(4)
+	c.AddEdge(0, 1, 3, 0)
+	c.AddEdge(1, 3, 10, 0)
+	c.AddEdge(0, 2, 10, 0)
+	c.AddEdge(2, 3, 10, 0)
+	c.SetEdgeCost(0, 1, 1)
+	c.SetEdgeCost(0, 2, 4)
+	c.SetNodeDemand(0, -5)
+	c.SetNodeDemand(3, 5)
+	if err := c.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() || c.Cost() != 3*1+2*4 {
+		t.Errorf("expected a valid circulation of cost 11, found cost %d", c.Cost())
+	}
+	if c.Flow(0, 1) != 3 || c.Flow(0, 2) != 2 {
+		t.Errorf("expected flows of 3 and 2, found %d and %d", c.Flow(0, 1), c.Flow(0, 2))
+	}
+	if err := flownet.SanityChecks.Circulation(c); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+	// the saturated cheap route leaves the tree, and the expensive route prices node 3.
+	basis := c.Basis()
+	if !reflect.DeepEqual(basis.Saturated, []flownet.Edge{{0, 1}}) {
+		t.Errorf("expected only edge (0, 1) to be saturated, found %v", basis.Saturated)
+	}
+	if c.Potential(3)-c.Potential(0) != 4 {
+		t.Errorf("expected a potential difference of 4 between nodes 0 and 3, found %d", c.Potential(3)-c.Potential(0))
+	}
+	checkPotentials(t, c, []Edge{{0, 1}, {1, 3}, {0, 2}, {2, 3}})
+
+	c.SetNodeDemand(3, 50)
+	if err := c.NetworkSimplex(); !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+}
+
+func TestNetworkSimplex_NegativeCycles(t *testing.T) {
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 3, 0)
+	c.AddEdge(1, 0, 4, 0)
+	c.AddEdge(1, 2, 5, 0)
+	c.AddEdge(2, 0, 5, 0)
+	c.SetEdgeCost(0, 1, -5)
+	c.SetEdgeCost(1, 0, 1)
+	c.SetEdgeCost(1, 2, 3)
+	c.SetEdgeCost(2, 0, 3)
+	if err := c.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 3 || c.Flow(1, 0) != 3 || c.Flow(1, 2) != 0 || c.Cost() != -12 {
+		t.Errorf("expected 3 units around the cycle 0 -> 1 -> 0 at cost -12, found cost %d", c.Cost())
+	}
+
+	c.AddEdge(0, 1, flownet.Infinite, 0)
+	c.AddEdge(1, 0, flownet.Infinite, 0)
+	if err := c.NetworkSimplex(); !errors.Is(err, flownet.ErrNegativeCycle) {
+		t.Errorf("expected ErrNegativeCycle, found %v", err)
+	}
+}
+
+func TestNetworkSimplex_UnmetEdgeDemand(t *testing.T) {
+	// the demand of 1 -> 2 sends 5 units to node 2, which has no way to send them on. The supply of node 0
+	// must not make up for them.
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 10, 0)
+	c.AddEdge(1, 2, 5, 5)
+	c.SetEdgeCost(0, 1, 1)
+	c.SetNodeDemand(0, -10)
+	if err := c.NetworkSimplex(); !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+
+	// once node 2 can return the flow to node 0, the 5 units circulate, starting from the basis found above.
+	c.AddEdge(2, 0, 5, 0)
+	if err := c.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 5 || c.Flow(1, 2) != 5 || c.Flow(2, 0) != 5 || c.Cost() != 5 {
+		t.Errorf("expected 5 units around the cycle at cost 5, found %d, %d, and %d at cost %d", c.Flow(0, 1), c.Flow(1, 2), c.Flow(2, 0), c.Cost())
+	}
+	checkPotentials(t, c, []Edge{{0, 1}, {1, 2}, {2, 0}})
+}
+
+func TestNetworkSimplex_WarmStart(t *testing.T) {
+	visitAllInstances(t, CircInstances, func(t *testing.T, path string, instance TestInstance) error {
+		c := instanceCirculation(instance)
+		edges := circulationEdges(instance)
+		if err := c.NetworkSimplex(); err != nil {
+			return nil
+		}
+		for step := 0; step < 10; step++ {
+			// make a small change, then solve again from the basis already found.
+			e := edges[(7*step)%len(edges)]
+			if step%2 == 0 {
+				c.SetEdgeCost(e.from, e.to, int64((13*step)%20-5))
+			} else {
+				demand := c.EdgeDemand(e.from, e.to)
+				c.AddEdge(e.from, e.to, demand+int64((3*step)%10), demand)
+			}
+			err := c.NetworkSimplex()
+			if !feasibleCirculation(&c, edges, instance.numNodes, nil) {
+				if !errors.Is(err, flownet.ErrInfeasible) {
+					t.Errorf("%s: step %d: expected ErrInfeasible, found %v", path, step, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: step %d: unexpected error: %v", path, step, err)
+			}
+			checkCheapestCirculation(t, fmt.Sprintf("%s: step %d", path, step), &c, edges, instance.numNodes, nil)
+			checkPotentials(t, c, edges)
+		}
+		return nil
+	})
+}
+
+func TestNetworkSimplex_Instances(t *testing.T) {
+	for _, suffix := range []string{CircInstances, FlowInstances} {
+		visitAllInstances(t, suffix, func(t *testing.T, path string, instance TestInstance) error {
+			edges := circulationEdges(instance)
+			for _, split := range []bool{false, true} {
+				c := instanceCirculation(instance)
+				if split {
+					c.SetNodeCapacity(instance.numNodes/2, 5)
+				}
+				err := c.NetworkSimplex()
+				if !feasibleCirculation(&c, edges, instance.numNodes, nil) {
+					if !errors.Is(err, flownet.ErrInfeasible) {
+						t.Errorf("%s: expected ErrInfeasible, found %v", path, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", path, err)
+				}
+				checkCheapestCirculation(t, path, &c, edges, instance.numNodes, nil)
+				if err := flownet.SanityChecks.Circulation(c); err != nil {
+					t.Errorf("%s: sanity checks failed: %v", path, err)
+				}
+				// potentials are only kept for the half of a split node which flow enters.
+				if !split {
+					checkPotentials(t, c, edges)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+func TestNetworkSimplex_SetBasis(t *testing.T) {
+	visitAllInstances(t, CircInstances, func(t *testing.T, path string, instance TestInstance) error {
+		solved := instanceCirculation(instance)
+		expected := solved.NetworkSimplex()
+
+		// a freshly built circulation which starts from the basis already found must end up with the same basis.
+		c := instanceCirculation(instance)
+		if err := c.SetBasis(solved.Basis()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		err := c.NetworkSimplex()
+		if (err == nil) != (expected == nil) {
+			t.Fatalf("%s: expected errors to agree, found %v and %v", path, expected, err)
+		}
+		if !reflect.DeepEqual(c.Basis(), solved.Basis()) {
+			t.Errorf("%s: expected basis %v, found %v", path, solved.Basis(), c.Basis())
+		}
+		if err != nil {
+			return nil
+		}
+		if c.Cost() != solved.Cost() {
+			t.Errorf("%s: expected cost %d, found %d", path, solved.Cost(), c.Cost())
+		}
+		for u := 0; u < instance.numNodes; u++ {
+			if c.Potential(u) != solved.Potential(u) {
+				t.Errorf("%s: expected potential %d for node %d, found %d", path, solved.Potential(u), u, c.Potential(u))
+			}
+		}
+		if err := flownet.SanityChecks.Circulation(c); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", path, err)
+		}
+		return nil
+	})
+
+	c := flownet.NewCirculation(2)
+	if err := c.SetBasis(flownet.SimplexBasis{Tree: []flownet.Edge{{From: 0, To: 2}}}); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+	if err := c.SetBasis(flownet.SimplexBasis{Saturated: []flownet.Edge{{From: 1, To: 1}}}); err == nil {
+		t.Errorf("expected an error for a node without a node capacity")
+	}
+}
+
+// instanceCirculation returns a circulation built from the provided instance, in which nodes joined to Source
+// supply 10 units and nodes joined to Sink demand 2 units, so that most instances have a valid circulation, and
+// each edge is given a cost which depends on its nodes. Instances which are not joined to Source or Sink are joined as a FlowNetwork would join them:
+// nodes which no edge enters supply flow, and nodes which no edge leaves demand it.
+func instanceCirculation(instance TestInstance) flownet.Circulation {
+	c := flownet.NewCirculation(instance.numNodes)
+	hasIn, hasOut := make(map[int]bool), make(map[int]bool)
+	for e, capacity := range instance.capacities {
+		hasIn[e.to], hasOut[e.from] = true, true
+		if e.from == flownet.Source {
+			c.SetNodeDemand(e.to, -10)
+		}
+		if e.to == flownet.Sink {
+			c.SetNodeDemand(e.from, 2)
+		}
+		if e.from < 0 || e.to < 0 || capacity <= 0 {
+			continue
+		}
+		c.AddEdge(e.from, e.to, capacity, instance.demands[e])
+		c.SetEdgeCost(e.from, e.to, int64((31*e.from+17*e.to)%23-5))
+	}
+	for u := 0; u < instance.numNodes; u++ {
+		if !hasOut[flownet.Source] && !hasIn[u] {
+			c.SetNodeDemand(u, -10)
+		}
+		if !hasIn[flownet.Sink] && !hasOut[u] {
+			c.SetNodeDemand(u, 2)
+		}
+	}
+	return c
+}
+
+// checkPotentials checks that the potentials found by NetworkSimplex prove the circulation cheapest: empty
+// edges must not be cheaper than the difference in potential across them, and saturated edges must not be
+// more expensive.
+func checkPotentials(t *testing.T, c flownet.Circulation, edges []Edge) {
+	t.Helper()
+	for _, e := range edges {
+		flow, capacity := c.Flow(e.from, e.to), c.Capacity(e.from, e.to)
+		reducedCost := c.EdgeCost(e.from, e.to) + c.Potential(e.from) - c.Potential(e.to)
+		if flow > c.EdgeDemand(e.from, e.to) && reducedCost > 0 || flow < capacity && reducedCost < 0 {
+			t.Errorf("edge %v with flow %d of %d has reduced cost %d", e, flow, capacity, reducedCost)
+		}
+	}
+}
+
+func TestNetworkSimplex_AddNode(t *testing.T) {
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 5, 0)
+	c.AddEdge(1, 2, 5, 0)
+	c.SetEdgeCost(0, 1, 1)
+	c.SetEdgeCost(1, 2, 1)
+	c.SetNodeCapacity(1, 3)
+	c.SetNodeDemand(0, -3)
+	c.SetNodeDemand(2, 3)
+	if err := c.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// a new node gives a second route around the split node, and must not disturb the basis kept for it.
+	u := c.AddNode()
+	c.AddEdge(0, u, 5, 0)
+	c.AddEdge(u, 2, 5, 0)
+	c.SetEdgeCost(0, u, 2)
+	c.SetEdgeCost(u, 2, 2)
+	c.SetNodeDemand(2, 5)
+	c.SetNodeDemand(0, -5)
+	if err := c.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Flow(0, 1) != 3 || c.Flow(0, u) != 2 || c.Cost() != 3*2+2*4 {
+		t.Errorf("expected flows of 3 and 2 at cost 14, found %d and %d at cost %d", c.Flow(0, 1), c.Flow(0, u), c.Cost())
+	}
+	if err := flownet.SanityChecks.Circulation(c); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+}
@@ -0,0 +1,191 @@
+package flownet
+
+import "fmt"
+
+// SetNodeCapacity limits the total flow which may pass through the provided node. Internally, the node is
+// split into two halves: every edge entering the node enters its in-half, every edge leaving the node
+// leaves its out-half, and an edge with the node's capacity joins the two. The out-half is hidden; Flow,
+// Capacity, Residual, and AddEdge all treat edges leaving the node as though it had not been split, and the
+// out-half never appears in the results of Outflow, MinCut, DecomposeFlow or TopSort, and it takes up no
+// node ID.
+//
+// An error is returned if nodeID is not a valid node ID, or if capacity is negative. Use Infinite to
+// remove the limit.
+func (g *FlowNetwork) SetNodeCapacity(nodeID int, capacity int64) error {
+	if nodeID < 0 || nodeID >= g.numNodes {
+		return fmt.Errorf("no node with ID %d is known", nodeID)
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacities must be non-negative")
+	}
+	u := internalID(nodeID)
+	if h, ok := g.outHalf[u]; ok {
+		g.capacity[edge{u, h}] = capacity
+		return nil
+	}
+	g.splitNode(u, capacity)
+	return nil
+}
+
+// NodeCapacity returns the capacity of the provided node, which is Infinite unless it has been set via
+// SetNodeCapacity.
+func (g FlowNetwork) NodeCapacity(nodeID int) int64 {
+	u := internalID(nodeID)
+	if h, ok := g.outHalf[u]; ok {
+		return g.capacity[edge{u, h}]
+	}
+	return Infinite
+}
+
+// splitNode moves every edge leaving node u so that it leaves a new hidden out-half instead, and joins u to
+// its out-half with an edge of the provided capacity. Any flow along the moved edges is kept.
+func (g *FlowNetwork) splitNode(u int, capacity int64) {
+	h := g.addHiddenNode(u)
+	through := int64(0)
+	for v := range g.adjacencyList[u] {
+		e := edge{u, v}
+		g.capacity[edge{h, v}] = g.capacity[e]
+		g.join(edge{h, v})
+		if flow := g.preflow[e]; flow > 0 {
+			g.preflow[edge{h, v}] = flow
+			through += flow
+		}
+		delete(g.capacity, e)
+		delete(g.preflow, e)
+	}
+	g.adjacencyList[u] = map[int]struct{}{h: {}}
+	g.adjacencyVisitList = nil
+	g.capacity[edge{u, h}] = capacity
+	if through > 0 {
+		g.preflow[edge{u, h}] = through
+	}
+
+	if g.outHalf == nil {
+		g.outHalf = make(map[int]int)
+		g.inHalf = make(map[int]int)
+	}
+	g.outHalf[u], g.inHalf[h] = h, u
+
+	// edges which left u now leave h.
+	for id, e := range g.edgeIDs {
+		if e.from == u {
+			g.edgeIDs[id].from = h
+		}
+	}
+	for pair, ids := range g.parallel {
+		if pair.from == u {
+			delete(g.parallel, pair)
+			g.parallel[edge{h, pair.to}] = ids
+		}
+	}
+}
+
+// addHiddenNode adds a node which is hidden from the programmer and returns its internal ID. Hidden nodes
+// take the internal IDs after those of every node, so that adding a node moves them up. The new node is not
+// connected to the source or sink, and is discharged just after the node with internal ID owner.
+func (g *FlowNetwork) addHiddenNode(owner int) int {
+	h := g.numInternalNodes()
+	g.insertNode(h)
+	g.searchTrees = nil
+	// nodeOrder runs backwards, so the hidden node goes just before its owner.
+	if len(g.nodeOrder) == g.numInternalNodes()-3 {
+		i := 0
+		for j, v := range g.nodeOrder {
+			if v == owner {
+				i = j
+				break
+			}
+		}
+		g.nodeOrder = append(g.nodeOrder[:i], append([]int{h}, g.nodeOrder[i:]...)...)
+	}
+	return h
+}
+
+// insertNode adds a node with the provided internal ID, which is joined to no other node. Every node with
+// an internal ID at least as large, all of which are hidden, moves up by one to make room for it.
+func (g *FlowNetwork) insertNode(u int) {
+	g.excess = append(g.excess[:u], append([]int64{0}, g.excess[u:]...)...)
+	g.label = append(g.label[:u], append([]int{0}, g.label[u:]...)...)
+	g.seen = append(g.seen[:u], append([]int{0}, g.seen[u:]...)...)
+	g.adjacencyList = append(g.adjacencyList[:u], append([]map[int]struct{}{make(map[int]struct{})}, g.adjacencyList[u:]...)...)
+	if u == len(g.adjacencyList)-1 {
+		return
+	}
+	move := func(e edge) edge { return edge{shifted(e.from, u), shifted(e.to, u)} }
+	capacity, preflow := make(map[edge]int64, len(g.capacity)), make(map[edge]int64, len(g.preflow))
+	for e, c := range g.capacity {
+		capacity[move(e)] = c
+	}
+	for e, f := range g.preflow {
+		preflow[move(e)] = f
+	}
+	g.capacity, g.preflow = capacity, preflow
+	for v, neighbors := range g.adjacencyList {
+		moved := make(map[int]struct{}, len(neighbors))
+		for w := range neighbors {
+			moved[shifted(w, u)] = struct{}{}
+		}
+		g.adjacencyList[v] = moved
+	}
+	outHalf, inHalf := make(map[int]int, len(g.outHalf)), make(map[int]int, len(g.inHalf))
+	for v, h := range g.outHalf {
+		outHalf[v], inHalf[shifted(h, u)] = shifted(h, u), v
+	}
+	g.outHalf, g.inHalf = outHalf, inHalf
+	for id, e := range g.edgeIDs {
+		g.edgeIDs[id] = move(e)
+	}
+	parallel := make(map[edge][]EdgeID, len(g.parallel))
+	for e, ids := range g.parallel {
+		parallel[move(e)] = ids
+	}
+	g.parallel = parallel
+	for i, v := range g.nodeOrder {
+		g.nodeOrder[i] = shifted(v, u)
+	}
+	g.adjacencyVisitList = nil
+	g.searchTrees = nil
+}
+
+// shifted returns the internal ID taken by the node with internal ID v once a node is inserted with
+// internal ID u.
+func shifted(v, u int) int {
+	if v >= u {
+		return v + 1
+	}
+	return v
+}
+
+// outHalfID returns the ID of the node from which flow leaves the node with the provided ID. This is the
+// hidden out-half of the node if it has a node capacity, and the node itself otherwise. Both IDs are
+// external IDs.
+func (g FlowNetwork) outHalfID(nodeID int) int {
+	if h, ok := g.outHalf[internalID(nodeID)]; ok {
+		return externalID(h)
+	}
+	return nodeID
+}
+
+// isHidden is true if the node with the provided internal ID is the hidden out-half of some other node.
+func (g FlowNetwork) isHidden(nodeID int) bool {
+	_, ok := g.inHalf[nodeID]
+	return ok
+}
+
+// visibleNode returns the internal ID of the node which the node with the provided internal ID belongs to;
+// the out-half of a split node belongs to the node itself.
+func (g FlowNetwork) visibleNode(nodeID int) int {
+	if u, ok := g.inHalf[nodeID]; ok {
+		return u
+	}
+	return nodeID
+}
+
+// visibleNodes returns the external IDs of every node which is not hidden, in ascending order.
+func (g FlowNetwork) visibleNodes() []int {
+	result := make([]int, 0, g.numNodes)
+	for u := 0; u < g.numNodes; u++ {
+		result = append(result, u)
+	}
+	return result
+}
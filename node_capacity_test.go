@@ -0,0 +1,141 @@
+package flownet_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestSetNodeCapacity(t *testing.T) {
+	solvers := map[string]func(*flownet.FlowNetwork) error{
+		"PushRelabel":             (*flownet.FlowNetwork).Solve,
+		"PushRelabelHighestLabel": (*flownet.FlowNetwork).PushRelabelHighestLabel,
+		"Dinic":                   (*flownet.FlowNetwork).Dinic,
+		"BoykovKolmogorov":        (*flownet.FlowNetwork).BoykovKolmogorov,
+	}
+	for name, solve := range solvers {
+		// two routes from 0 to 3 pass through node 1, which can only carry 4 units of flow.
+		graph := flownet.NewFlowNetwork(4)
+		graph.AddEdge(0, 1, 10)
+		graph.AddEdge(0, 2, 3)
+		graph.AddEdge(2, 1, 3)
+		if err := graph.SetNodeCapacity(1, 4); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		graph.AddEdge(1, 3, 10)
+		if err := solve(&graph); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if graph.Outflow() != 4 {
+			t.Errorf("%s: expected outflow of 4, found %d", name, graph.Outflow())
+		}
+		if graph.Flow(1, 3) != 4 || graph.Capacity(1, 3) != 10 || graph.Residual(1, 3) != 6 {
+			t.Errorf("%s: expected flow 4 of capacity 10 along edge from 1 to 3, found %d of %d with residual %d", name,
+				graph.Flow(1, 3), graph.Capacity(1, 3), graph.Residual(1, 3))
+		}
+		if graph.NodeCapacity(1) != 4 || graph.NodeCapacity(0) != flownet.Infinite {
+			t.Errorf("%s: unexpected node capacities %d and %d", name, graph.NodeCapacity(1), graph.NodeCapacity(0))
+		}
+		if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+}
+
+func TestSetNodeCapacity_Hidden(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(1, 2, 5)
+	graph.SetNodeCapacity(1, 2)
+	graph.PushRelabel()
+
+	order, err := flownet.TopSort(graph, func(i, j int) bool { return i < j })
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(order, []int{0, 1, 2}) {
+		t.Errorf("expected topological order [0 1 2], found %v", order)
+	}
+
+	cut := graph.MinCut()
+	expected := flownet.Cut{
+		SourceSide: []int{flownet.Source, 0, 1},
+		SinkSide:   []int{flownet.Sink, 2},
+		Nodes:      []int{1},
+		Capacity:   2,
+	}
+	if !reflect.DeepEqual(cut, expected) {
+		t.Errorf("expected cut %+v, found %+v", expected, cut)
+	}
+
+	paths := graph.DecomposeFlow()
+	if len(paths) != 1 || !reflect.DeepEqual(paths[0].Nodes, []int{flownet.Source, 0, 1, 2, flownet.Sink}) {
+		t.Errorf("expected a single path through each node, found %v", paths)
+	}
+
+	if _, err := graph.AddEdge(0, 3, 1); err == nil {
+		t.Errorf("expected an error when connecting to a hidden node")
+	}
+	if err := graph.SetNodeOrder([]int{2, 1, 0}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := graph.SetNodeCapacity(1, -1); err == nil {
+		t.Errorf("expected an error for a negative capacity")
+	}
+}
+
+func TestSetNodeCapacity_AddNode(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(1, 2, 5)
+	graph.SetNodeCapacity(1, 2)
+	// the hidden half of node 1 must not take up the next node ID.
+	if id := graph.AddNode(); id != 3 {
+		t.Fatalf("expected AddNode to return 3, found %d", id)
+	}
+	graph.AddEdge(0, 3, 4)
+	graph.AddEdge(3, 2, 4)
+	graph.Dinic()
+	if graph.Outflow() != 6 {
+		t.Errorf("expected outflow of 6, found %d", graph.Outflow())
+	}
+	if graph.Flow(0, 1) != 2 || graph.Flow(1, 2) != 2 || graph.Flow(3, 2) != 4 {
+		t.Errorf("expected flows of 2, 2, and 4, found %d, %d, and %d", graph.Flow(0, 1), graph.Flow(1, 2), graph.Flow(3, 2))
+	}
+	order, err := flownet.TopSort(graph, func(i, j int) bool { return i < j })
+	if err != nil || len(order) != 4 || order[0] != 0 || order[3] != 2 {
+		t.Errorf("expected a topological order of the four visible nodes, found %v (error %v)", order, err)
+	}
+	if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+}
+
+func TestSetNodeCapacity_Random(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 20; trial++ {
+		graph := randomNetwork(r, 50)
+		// splitting nodes by hand must give the same result.
+		split := flownet.NewFlowNetwork(100)
+		for u := 0; u < 50; u++ {
+			capacity := r.Int63n(200)
+			graph.SetNodeCapacity(u, capacity)
+			split.AddEdge(u, u+50, capacity)
+			for v := 0; v < 50; v++ {
+				if c := graph.Capacity(u, v); c > 0 {
+					split.AddEdge(u+50, v, c)
+				}
+			}
+		}
+		graph.Dinic()
+		split.Dinic()
+		if graph.Outflow() != split.Outflow() {
+			t.Errorf("trial %d: expected outflow of %d, found %d", trial, split.Outflow(), graph.Outflow())
+		}
+		if cut := graph.MinCut(); cut.Capacity != graph.Outflow() {
+			t.Errorf("trial %d: cut capacity %d does not equal max flow %d", trial, cut.Capacity, graph.Outflow())
+		}
+	}
+}
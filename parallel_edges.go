@@ -0,0 +1,178 @@
+package flownet
+
+import "fmt"
+
+// An EdgeID identifies an edge added to a FlowNetwork via AddEdge or AddParallelEdge. Unlike a pair of node
+// IDs, an EdgeID tells apart parallel edges joining the same two nodes.
+type EdgeID int
+
+// AddParallelEdge adds a new edge with the provided capacity to the flow network, alongside any edges
+// already joining the same two nodes, and returns an ID which refers to it. Flow, Capacity, and Residual
+// report totals across every edge joining two nodes, while FlowByID, CapacityByID, and ResidualByID report
+// on a single edge. Adding a parallel edge between two nodes joined by an undirected edge replaces the
+// undirected edge.
+//
+// Errors are returned as for AddEdge. An error wrapping ErrCapacityOverflow is returned if the total
+// capacity of the edges joining the two nodes cannot be stored in an int64.
+func (g *FlowNetwork) AddParallelEdge(fromID, toID int, capacity int64) (EdgeID, error) {
+	if err := g.checkEdge(fromID, toID, capacity); err != nil {
+		return -1, err
+	}
+	e := newEdge(g.outHalfID(fromID), toID)
+	total, err := g.totalCapacity(e, -1, capacity)
+	if err != nil {
+		return -1, err
+	}
+	g.connectDirected(fromID, toID)
+	return g.addEdgeByID(e, capacity, total), nil
+}
+
+// FlowByID returns the flow along the provided edge. Before PushRelabel is called this method returns 0.
+// The flow between two nodes is shared among the edges joining them in the order they were added, so
+// that each edge is filled up to its capacity before the next carries any flow.
+func (g FlowNetwork) FlowByID(id EdgeID) int64 {
+	e, ok := g.edgeByID(id)
+	if !ok {
+		return 0
+	}
+	remaining := g.preflow[e]
+	ids := g.parallel[e]
+	for i, other := range ids {
+		flow := remaining
+		if i < len(ids)-1 {
+			flow = min64(remaining, g.edgeCapacity[other])
+		}
+		if other == id {
+			return flow
+		}
+		remaining -= flow
+	}
+	return 0
+}
+
+// CapacityByID returns the capacity of the provided edge.
+func (g FlowNetwork) CapacityByID(id EdgeID) int64 {
+	if _, ok := g.edgeByID(id); !ok {
+		return 0
+	}
+	return g.edgeCapacity[id]
+}
+
+// ResidualByID returns the residual flow along the provided edge, defined as capacity - flow. The residual
+// flow along an edge with Infinite capacity is Infinite.
+func (g FlowNetwork) ResidualByID(id EdgeID) int64 {
+	if _, ok := g.edgeByID(id); !ok {
+		return 0
+	}
+	if g.edgeCapacity[id] == Infinite {
+		return Infinite
+	}
+	return g.edgeCapacity[id] - g.FlowByID(id)
+}
+
+// SetCapacity changes the capacity of the provided edge. Any flow already found is kept, so the network can
+// be solved again via Resolve. An error is returned if no edge has the provided ID, or if the capacity is
+// negative. An error wrapping ErrCapacityOverflow is returned if the total capacity of the edges joining
+// the same two nodes cannot be stored in an int64.
+func (g *FlowNetwork) SetCapacity(id EdgeID, capacity int64) error {
+	e, ok := g.edgeByID(id)
+	if !ok {
+		return fmt.Errorf("no edge with ID %d is known", id)
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacities must be non-negative")
+	}
+	total, err := g.totalCapacity(e, id, capacity)
+	if err != nil {
+		return err
+	}
+	g.edgeCapacity[id] = capacity
+	g.capacity[e] = total
+	return nil
+}
+
+// edgeByID returns the edge carrying the capacity of the edge with the provided ID.
+func (g FlowNetwork) edgeByID(id EdgeID) (edge, bool) {
+	if id < 0 || int(id) >= len(g.edgeIDs) {
+		return edge{}, false
+	}
+	return g.edgeIDs[id], true
+}
+
+// addEdgeByID adds a new edge with the provided capacity alongside the edges with IDs which already join
+// the nodes of e, and returns its ID. total is the total capacity of all of these edges, as found by
+// totalCapacity. Any capacity between the nodes which does not belong to such an edge is replaced.
+func (g *FlowNetwork) addEdgeByID(e edge, capacity, total int64) EdgeID {
+	id := EdgeID(len(g.edgeIDs))
+	g.edgeIDs = append(g.edgeIDs, e)
+	g.edgeCapacity = append(g.edgeCapacity, capacity)
+	if g.parallel == nil {
+		g.parallel = make(map[edge][]EdgeID)
+	}
+	g.parallel[e] = append(g.parallel[e], id)
+	g.capacity[e] = total
+	g.join(e)
+	return id
+}
+
+// totalCapacity returns the total capacity of the edges with IDs which join the nodes of e, once the
+// capacity of the edge with the provided ID is replaced; if id is -1, the capacity is that of a new edge.
+func (g FlowNetwork) totalCapacity(e edge, id EdgeID, capacity int64) (int64, error) {
+	total := int64(0)
+	if id < 0 {
+		total = capacity
+	}
+	for _, other := range g.parallel[e] {
+		c := g.edgeCapacity[other]
+		if other == id {
+			c = capacity
+		}
+		var ok bool
+		if total, ok = addCapacity(total, c); !ok {
+			return 0, fmt.Errorf("%w: the total capacity of the edges from %d to %d", ErrCapacityOverflow,
+				externalID(g.visibleNode(e.from)), externalID(e.to))
+		}
+	}
+	return total, nil
+}
+
+// setPairCapacity sets the total capacity of the edges joining the nodes of e. If some of them have IDs,
+// the first of those is given the capacity, and the rest none.
+func (g *FlowNetwork) setPairCapacity(e edge, capacity int64) {
+	for i, id := range g.parallel[e] {
+		g.edgeCapacity[id] = 0
+		if i == 0 {
+			g.edgeCapacity[id] = capacity
+		}
+	}
+	g.capacity[e] = capacity
+	g.join(e)
+}
+
+// multiplicity returns the number of edges with positive capacity joining the nodes of e, counting the
+// capacity of e as a single edge if it was not added via AddEdge or AddParallelEdge.
+func (g FlowNetwork) multiplicity(e edge) int {
+	ids, ok := g.parallel[e]
+	if !ok {
+		if g.capacity[e] > 0 {
+			return 1
+		}
+		return 0
+	}
+	result := 0
+	for _, id := range ids {
+		if g.edgeCapacity[id] > 0 {
+			result++
+		}
+	}
+	return result
+}
+
+// visibleEdge returns the edge between two visible nodes which the provided edge belongs to, by internal IDs.
+// Returns false for edges which join the two halves of a split node.
+func (g FlowNetwork) visibleEdge(e edge) (edge, bool) {
+	if _, ok := g.inHalf[e.to]; ok {
+		return edge{}, false
+	}
+	return edge{g.visibleNode(e.from), e.to}, true
+}
@@ -0,0 +1,141 @@
+package flownet_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestParallelEdges(t *testing.T) {
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(flownet.Source, 0, 20)
+	first, _ := graph.AddEdge(0, 1, 3)
+	second, _ := graph.AddParallelEdge(0, 1, 4)
+	third, _ := graph.AddParallelEdge(0, 1, 5)
+	graph.AddEdge(1, 2, 20)
+	graph.AddEdge(2, flownet.Sink, 20)
+	if first == second || second == third {
+		t.Fatalf("expected distinct edge IDs, found %d, %d, and %d", first, second, third)
+	}
+	if graph.Capacity(0, 1) != 12 {
+		t.Errorf("expected a total capacity of 12, found %d", graph.Capacity(0, 1))
+	}
+	graph.Dinic()
+	if graph.Outflow() != 12 {
+		t.Errorf("expected max flow of 12, found %d", graph.Outflow())
+	}
+	for id, cap := range map[flownet.EdgeID]int64{first: 3, second: 4, third: 5} {
+		if graph.CapacityByID(id) != cap || graph.FlowByID(id) != cap || graph.ResidualByID(id) != 0 {
+			t.Errorf("expected edge %d to be saturated with flow %d, found capacity %d, flow %d, and residual %d",
+				id, cap, graph.CapacityByID(id), graph.FlowByID(id), graph.ResidualByID(id))
+		}
+	}
+	if graph.Flow(0, 1) != 12 || graph.Residual(0, 1) != 0 {
+		t.Errorf("expected a total flow of 12 with no residual, found %d and %d", graph.Flow(0, 1), graph.Residual(0, 1))
+	}
+	if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+
+	cut := graph.MinCut()
+	expected := []flownet.Edge{{0, 1}, {0, 1}, {0, 1}}
+	if !reflect.DeepEqual(cut.Edges, expected) || cut.Capacity != 12 {
+		t.Errorf("expected cut edges %v with capacity 12, found %v with capacity %d", expected, cut.Edges, cut.Capacity)
+	}
+	if !reflect.DeepEqual(cut.SourceSide, []int{flownet.Source, 0}) {
+		t.Errorf("expected source side [Source 0], found %v", cut.SourceSide)
+	}
+	for _, path := range graph.DecomposeFlow() {
+		if !reflect.DeepEqual(path.Nodes, []int{flownet.Source, 0, 1, 2, flownet.Sink}) {
+			t.Errorf("expected every path to visit only visible nodes, found %v", path.Nodes)
+		}
+	}
+	if paths, _ := flownet.EdgeDisjointPaths(graph, 0, 2); len(paths) != 1 {
+		t.Errorf("expected 1 edge-disjoint path, found %v", paths)
+	}
+	if paths, _ := flownet.EdgeDisjointPaths(graph, 0, 1); len(paths) != 3 {
+		t.Errorf("expected 3 edge-disjoint paths along parallel edges, found %v", paths)
+	}
+}
+
+func TestSetCapacity(t *testing.T) {
+	graph := flownet.NewFlowNetwork(2)
+	first, _ := graph.AddEdge(0, 1, 3)
+	second, _ := graph.AddParallelEdge(0, 1, 4)
+	graph.PushRelabel()
+	if graph.Outflow() != 7 {
+		t.Errorf("expected max flow of 7, found %d", graph.Outflow())
+	}
+	if err := graph.SetCapacity(second, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	graph.Resolve()
+	if graph.Outflow() != 4 || graph.FlowByID(first) != 3 || graph.FlowByID(second) != 1 {
+		t.Errorf("expected flows of 3 and 1, found %d and %d", graph.FlowByID(first), graph.FlowByID(second))
+	}
+	if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+	if err := graph.SetCapacity(2, 1); err == nil {
+		t.Errorf("expected an error for an unknown edge")
+	}
+	if err := graph.SetCapacity(first, -1); err == nil {
+		t.Errorf("expected an error for a negative capacity")
+	}
+	if _, err := graph.AddEdge(0, 0, 1); err == nil {
+		t.Errorf("expected an error for a self-loop")
+	}
+}
+
+func TestAddEdge_Replace(t *testing.T) {
+	// adding an edge twice replaces its capacity, and adds no node.
+	graph := flownet.NewFlowNetwork(2)
+	first, _ := graph.AddEdge(0, 1, 3)
+	second, _ := graph.AddEdge(0, 1, 4)
+	if first != second || graph.Capacity(0, 1) != 4 || graph.CapacityByID(first) != 4 {
+		t.Errorf("expected the same edge with capacity 4, found edges %d and %d with capacity %d", first, second, graph.Capacity(0, 1))
+	}
+	parallel, _ := graph.AddParallelEdge(0, 1, 2)
+	if id, _ := graph.AddEdge(0, 1, 5); id != first || graph.Capacity(0, 1) != 7 || graph.CapacityByID(parallel) != 2 {
+		t.Errorf("expected only edge %d to be replaced, found edge %d and a total capacity of %d", first, id, graph.Capacity(0, 1))
+	}
+	if _, err := graph.AddParallelEdge(0, 1, math.MaxInt64-1); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow, found %v", err)
+	}
+	if id := graph.AddNode(); id != 2 {
+		t.Errorf("expected the next node to have ID 2, found %d", id)
+	}
+	if _, err := graph.AddEdge(2, 1, 1); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	graph.Dinic()
+	if graph.Outflow() != 8 || graph.FlowByID(first) != 5 || graph.FlowByID(parallel) != 2 {
+		t.Errorf("expected a max flow of 8 with flows of 5 and 2, found %d with flows %d and %d", graph.Outflow(), graph.FlowByID(first), graph.FlowByID(parallel))
+	}
+}
+
+func TestParallelEdges_NodeCapacity(t *testing.T) {
+	graph := flownet.NewFlowNetwork(2)
+	first, _ := graph.AddEdge(0, 1, 3)
+	graph.SetNodeCapacity(0, 5)
+	second, _ := graph.AddParallelEdge(0, 1, 4)
+	if err := graph.SetNodeOrder([]int{1, 0}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	graph.PushRelabel()
+	if graph.Outflow() != 5 || graph.Flow(0, 1) != 5 {
+		t.Errorf("expected max flow of 5 limited by the node capacity, found %d", graph.Outflow())
+	}
+	if graph.FlowByID(first)+graph.FlowByID(second) != 5 {
+		t.Errorf("expected flows summing to 5, found %d and %d", graph.FlowByID(first), graph.FlowByID(second))
+	}
+	if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+	if cut := graph.MinCut(); cut.Capacity != 5 {
+		t.Errorf("expected a cut of capacity 5, found %+v", cut)
+	}
+}
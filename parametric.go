@@ -0,0 +1,379 @@
+package flownet
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// A LinearCapacity is the capacity Base + Slope*lambda of an edge, which depends on a parameter lambda.
+type LinearCapacity struct {
+	Base, Slope int64
+}
+
+// at returns the capacity for the provided value of lambda. Returns false if it cannot be stored in an int64.
+func (c LinearCapacity) at(lambda int64) (int64, bool) {
+	product, ok := mulCost(lambda, c.Slope)
+	if !ok {
+		return 0, false
+	}
+	return addCost(c.Base, product)
+}
+
+// A Breakpoint is a value of the parameter of a parametric flow network at which its minimum cut changes.
+type Breakpoint struct {
+	// Lambda is the smallest value of the parameter for which Cut is the source-minimal minimum cut.
+	Lambda int64
+	// Flow is the value of the maximum flow when the parameter equals Lambda, or Infinite if it is larger than
+	// any finite capacity.
+	Flow int64
+	// Cut is the source-minimal minimum cut for every value of the parameter from Lambda up to, but not
+	// including, the next breakpoint. Its capacity is that for the parameter equal to Lambda.
+	Cut Cut
+}
+
+// ParametricMaxFlow solves the network for every integer value of a parameter lambda between lo and hi,
+// inclusive, and returns the breakpoints at which its source-minimal minimum cut changes, in ascending
+// order. The first breakpoint is always lo. The capacity of the edge from the source to each node in
+// source, and from each node in sink to the sink, is given by the node's LinearCapacity; other edges keep
+// their capacity. As with AddEdge, this makes the programmer responsible for managing all edges to the
+// source or the sink. When ParametricMaxFlow returns, the network holds the capacities and a maximum flow
+// for lambda = hi.
+//
+// Source capacities must be non-decreasing in lambda, and sink capacities must be non-increasing. As shown
+// by Gallo, Grigoriadis and Tarjan, the minimum cuts are then nested: the source side of each cut contains
+// that of every cut before it, so there are fewer breakpoints than nodes. The capacity of each cut is linear
+// in lambda, so the capacity of the minimum cut is a concave, piecewise linear function of lambda, whose
+// pieces meet where the minimum cut changes. These points are found exactly via the breakpoint algorithm of
+// Gallo, Grigoriadis and Tarjan: given minimum cuts for two values of lambda, the network is solved where
+// the capacities of the two cuts are equal. Either both cuts are minimum there, which makes it a breakpoint,
+// or a cheaper cut is found, and the search continues on both sides of it. Each solve contracts the source
+// side of the lower cut into the source and the sink side of the upper cut into the sink, so it only
+// involves the nodes between the two cuts. Breakpoints which fall between two integers are reported at the
+// next integer, where the cut found is the one that holds there.
+//
+// The network is solved in full for lo and hi, and then once via Dinic's algorithm for each breakpoint and
+// once more for each cut found along the way, so the running time is that of O(k) maximum flow runs, where k
+// is the number of breakpoints. Contraction keeps each run small when the breakpoints split the nodes
+// evenly, but this is not the bound of a single push-relabel run achieved by the algorithm of Gallo,
+// Grigoriadis and Tarjan which runs push-relabel on the network for all values of lambda at once.
+//
+// An error is returned if lo > hi, if any capacity is negative for lo or hi, if any source capacity
+// decreases or any sink capacity increases, or if the network cannot be solved for some value of lambda.
+// An error wrapping ErrCapacityOverflow is returned if any capacity scaled to solve for a fractional value
+// of lambda cannot be stored.
+func (g *FlowNetwork) ParametricMaxFlow(lo, hi int64, source, sink map[int]LinearCapacity) ([]Breakpoint, error) {
+	if lo > hi {
+		return nil, fmt.Errorf("the parameter range is empty, found lo %d > hi %d", lo, hi)
+	}
+	for nodeID, capacity := range source {
+		if capacity.Slope < 0 {
+			return nil, fmt.Errorf("source capacities must be non-decreasing, found slope %d for node %d", capacity.Slope, nodeID)
+		}
+	}
+	for nodeID, capacity := range sink {
+		if capacity.Slope > 0 {
+			return nil, fmt.Errorf("sink capacities must be non-increasing, found slope %d for node %d", capacity.Slope, nodeID)
+		}
+	}
+	p := parametricSolver{g: g, source: source, sink: sink}
+	sourceSide, err := p.solve(lo)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.prepare(); err != nil {
+		return nil, err
+	}
+	low := p.cut(sourceSide)
+	if hi > lo {
+		if sourceSide, err = p.solve(hi); err != nil {
+			return nil, err
+		}
+		if err := p.search(low, p.cut(sourceSide)); err != nil {
+			return nil, err
+		}
+	}
+
+	breakpoints := []Breakpoint{p.breakpoint(low, lo)}
+	for _, cut := range p.found {
+		// cut only becomes source-minimal once lambda exceeds the value at which it becomes minimum.
+		lambda := new(big.Int).Div(cut.at.Num(), cut.at.Denom())
+		lambda.Add(lambda, big.NewInt(1))
+		if !lambda.IsInt64() || lambda.Int64() > hi {
+			break
+		}
+		bp := p.breakpoint(cut, lambda.Int64())
+		if last := &breakpoints[len(breakpoints)-1]; last.Lambda == bp.Lambda {
+			*last = bp
+		} else {
+			breakpoints = append(breakpoints, bp)
+		}
+	}
+	return breakpoints, nil
+}
+
+// parametricSolver stores the state of a search for the breakpoints of a parametric flow network.
+type parametricSolver struct {
+	g            *FlowNetwork
+	source, sink map[int]LinearCapacity
+	// capacity stores the capacity of each edge of the network as a function of lambda, by internal IDs.
+	capacity map[edge]LinearCapacity
+	// incident stores the edges which meet each node, by internal IDs.
+	incident [][]edge
+	// found stores each cut at which the minimum cut changes, in ascending order of the value of lambda at
+	// which it becomes minimum.
+	found []parametricCut
+}
+
+// parametricCut is a cut of a parametric flow network, along with its capacity as a function of lambda.
+type parametricCut struct {
+	// sourceSide is true for each node on the source side of the cut, by internal IDs.
+	sourceSide []bool
+	// base and slope give the capacity of the cut as base + slope*lambda.
+	base, slope *big.Int
+	// at is the value of lambda at which the cut becomes a minimum cut, once known.
+	at *big.Rat
+}
+
+// solve sets the capacities of the network for the provided value of lambda, finds a maximum flow, and
+// returns the source side of its source-minimal minimum cut, by internal IDs.
+func (p *parametricSolver) solve(lambda int64) ([]bool, error) {
+	set := func(fromID, toID int, capacity LinearCapacity) error {
+		c, ok := capacity.at(lambda)
+		if !ok {
+			return fmt.Errorf("%w: the capacity of the edge from %d to %d for lambda %d", ErrCapacityOverflow, fromID, toID, lambda)
+		}
+		return p.g.setEdge(fromID, toID, c)
+	}
+	for nodeID, capacity := range p.source {
+		if err := set(Source, nodeID, capacity); err != nil {
+			return nil, err
+		}
+	}
+	for nodeID, capacity := range p.sink {
+		if err := set(nodeID, Sink, capacity); err != nil {
+			return nil, err
+		}
+	}
+	if err := p.g.resolve(); err != nil {
+		p.g.clearFlow()
+		return nil, err
+	}
+	return p.g.residualReachable(sourceID), nil
+}
+
+// prepare finds the capacity of each edge as a function of lambda, once every edge has been added.
+func (p *parametricSolver) prepare() error {
+	g := p.g
+	p.capacity = make(map[edge]LinearCapacity, len(g.capacity))
+	p.incident = make([][]edge, g.numInternalNodes())
+	for e, capacity := range g.capacity {
+		p.capacity[e] = LinearCapacity{Base: capacity}
+		p.incident[e.from] = append(p.incident[e.from], e)
+		p.incident[e.to] = append(p.incident[e.to], e)
+	}
+	for nodeID, capacity := range p.source {
+		p.capacity[newEdge(Source, nodeID)] = capacity
+	}
+	for nodeID, capacity := range p.sink {
+		p.capacity[newEdge(g.outHalfID(nodeID), Sink)] = capacity
+	}
+	if g.manualSource {
+		return nil
+	}
+	// edges from the source are managed automatically, and carry the outgoing capacity of their node.
+	for e := range g.capacity {
+		if e.from != sourceID {
+			continue
+		}
+		total := LinearCapacity{}
+		for v := range g.adjacencyList[e.to] {
+			if v == sourceID || (v == sinkID && !g.manualSink) {
+				continue
+			}
+			capacity := p.capacity[edge{e.to, v}]
+			if capacity.Base == Infinite && capacity.Slope == 0 {
+				total = capacity
+				break
+			}
+			base, ok := addCost(total.Base, capacity.Base)
+			slope, ok2 := addCost(total.Slope, capacity.Slope)
+			if !ok || !ok2 {
+				return fmt.Errorf("%w: the outgoing capacity of node %d", ErrCapacityOverflow, externalID(e.to))
+			}
+			total = LinearCapacity{Base: base, Slope: slope}
+		}
+		p.capacity[e] = total
+	}
+	return nil
+}
+
+// cut returns the cut with the provided source side, along with its capacity as a function of lambda. Edges
+// with Infinite capacity never cross the minimum cuts it is used for.
+func (p *parametricSolver) cut(sourceSide []bool) parametricCut {
+	cut := parametricCut{sourceSide: sourceSide, base: new(big.Int), slope: new(big.Int)}
+	for e, capacity := range p.capacity {
+		if crosses(sourceSide, e) {
+			cut.add(capacity, 1)
+		}
+	}
+	return cut
+}
+
+// add adds the provided capacity, multiplied by sign, to the capacity of the cut.
+func (cut parametricCut) add(capacity LinearCapacity, sign int64) {
+	cut.base.Add(cut.base, new(big.Int).Mul(big.NewInt(capacity.Base), big.NewInt(sign)))
+	cut.slope.Add(cut.slope, new(big.Int).Mul(big.NewInt(capacity.Slope), big.NewInt(sign)))
+}
+
+// search records every cut at which the minimum cut changes between low and high, which are the
+// source-minimal minimum cuts for two values of lambda.
+func (p *parametricSolver) search(low, high parametricCut) error {
+	if low.slope.Cmp(high.slope) == 0 {
+		// the capacities of two minimum cuts only have the same slope if the cuts are the same.
+		return nil
+	}
+	// the capacities of the cuts are equal where base + slope*lambda agree.
+	at := new(big.Rat).SetFrac(
+		new(big.Int).Sub(high.base, low.base),
+		new(big.Int).Sub(low.slope, high.slope),
+	)
+	mid, err := p.solveBetween(low, high, at)
+	if err != nil {
+		return err
+	}
+	if capacityAt(mid, at).Cmp(capacityAt(low, at)) == 0 {
+		high.at = at
+		p.found = append(p.found, high)
+		return nil
+	}
+	if err := p.search(low, mid); err != nil {
+		return err
+	}
+	return p.search(mid, high)
+}
+
+// solveBetween returns the source-minimal minimum cut for the provided value of lambda, which lies between
+// the values for which low and high are source-minimal minimum cuts. Since the minimum cuts are nested,
+// every node on the source side of low is contracted into the source, and every node on the sink side of
+// high into the sink, so only the nodes between the two cuts are solved for. Capacities are scaled by the
+// denominator of lambda, so that every capacity is an integer.
+func (p *parametricSolver) solveBetween(low, high parametricCut, at *big.Rat) (parametricCut, error) {
+	index := make(map[int]int)
+	var between []int
+	for u := range low.sourceSide {
+		if high.sourceSide[u] && !low.sourceSide[u] {
+			index[u] = len(between) + 2
+			between = append(between, u)
+		}
+	}
+	contracted := func(u int) int {
+		if low.sourceSide[u] {
+			return sourceID
+		}
+		if !high.sourceSide[u] {
+			return sinkID
+		}
+		return index[u]
+	}
+
+	// each edge joining two nodes between the cuts is met from both of them, but only used once.
+	eachEdge := func(visit func(e edge) error) error {
+		for _, u := range between {
+			for _, e := range p.incident[u] {
+				if _, ok := index[e.from]; ok && e.from != u {
+					continue
+				}
+				if err := visit(e); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	sub := NewFlowNetwork(len(between))
+	sub.enableManualSource()
+	sub.enableManualSink()
+	err := eachEdge(func(e edge) error {
+		from, to := contracted(e.from), contracted(e.to)
+		if from == sinkID || to == sourceID {
+			return nil
+		}
+		capacity, err := scaledCapacity(p.capacity[e], at)
+		if err != nil {
+			return err
+		}
+		key := edge{from, to}
+		total, ok := addCapacity(sub.capacity[key], capacity)
+		if !ok {
+			return fmt.Errorf("%w: the capacities scaled to solve for lambda %s", ErrCapacityOverflow, at.RatString())
+		}
+		sub.capacity[key] = total
+		sub.adjacencyList[from][to] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return parametricCut{}, err
+	}
+	if err := sub.prepare(); err != nil {
+		return parametricCut{}, err
+	}
+	sub.dinic()
+
+	reachable := sub.residualReachable(sourceID)
+	sourceSide := append([]bool{}, low.sourceSide...)
+	for _, u := range between {
+		sourceSide[u] = reachable[index[u]]
+	}
+	// only edges meeting the nodes between the cuts can cross one cut but not the other.
+	result := parametricCut{sourceSide: sourceSide, base: new(big.Int).Set(low.base), slope: new(big.Int).Set(low.slope)}
+	eachEdge(func(e edge) error {
+		if crosses(sourceSide, e) {
+			result.add(p.capacity[e], 1)
+		}
+		if crosses(low.sourceSide, e) {
+			result.add(p.capacity[e], -1)
+		}
+		return nil
+	})
+	return result, nil
+}
+
+// breakpoint returns the breakpoint at which the provided cut becomes the source-minimal minimum cut. The
+// flow is Infinite if the capacity of the cut is larger than any finite capacity, as it is when the cut
+// crosses an edge with Infinite capacity.
+func (p *parametricSolver) breakpoint(cut parametricCut, lambda int64) Breakpoint {
+	flow := new(big.Int).Mul(cut.slope, big.NewInt(lambda))
+	flow.Add(flow, cut.base)
+	result := Breakpoint{Lambda: lambda, Flow: Infinite, Cut: p.g.cut(cut.sourceSide)}
+	if flow.IsInt64() && flow.Int64() <= maxFiniteCapacity {
+		result.Flow = flow.Int64()
+	}
+	result.Cut.Capacity = result.Flow
+	return result
+}
+
+// crosses is true if the provided edge leads from the source side of a cut to its sink side.
+func crosses(sourceSide []bool, e edge) bool {
+	return sourceSide[e.from] && !sourceSide[e.to]
+}
+
+// capacityAt returns the capacity of the provided cut for the provided value of lambda.
+func capacityAt(cut parametricCut, lambda *big.Rat) *big.Rat {
+	result := new(big.Rat).Mul(new(big.Rat).SetInt(cut.slope), lambda)
+	return result.Add(result, new(big.Rat).SetInt(cut.base))
+}
+
+// scaledCapacity returns the provided capacity for the provided value of lambda, multiplied by the
+// denominator of lambda. Infinite capacities stay Infinite.
+func scaledCapacity(capacity LinearCapacity, lambda *big.Rat) (int64, error) {
+	if capacity.Base == Infinite && capacity.Slope == 0 {
+		return Infinite, nil
+	}
+	result := new(big.Int).Mul(big.NewInt(capacity.Base), lambda.Denom())
+	result.Add(result, new(big.Int).Mul(big.NewInt(capacity.Slope), lambda.Num()))
+	if !result.IsInt64() || result.Int64() > maxFiniteCapacity {
+		return 0, fmt.Errorf("%w: the capacities scaled to solve for lambda %s", ErrCapacityOverflow, lambda.RatString())
+	}
+	return result.Int64(), nil
+}
@@ -0,0 +1,147 @@
+package flownet_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestParametricMaxFlow(t *testing.T) {
+	// each node is joined to the source with capacity lambda, and to the sink with a fixed capacity.
+	graph := flownet.NewFlowNetwork(3)
+	source, sink := make(map[int]flownet.LinearCapacity), make(map[int]flownet.LinearCapacity)
+	for nodeID, capacity := range []int64{3, 5, 5} {
+		source[nodeID] = flownet.LinearCapacity{Slope: 1}
+		sink[nodeID] = flownet.LinearCapacity{Base: capacity}
+	}
+	breakpoints, err := graph.ParametricMaxFlow(0, 10, source, sink)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []struct {
+		lambda, flow int64
+		sourceSide   []int
+	}{
+		{0, 0, []int{flownet.Source}},
+		{4, 11, []int{flownet.Source, 0}},
+		{6, 13, []int{flownet.Source, 0, 1, 2}},
+	}
+	if len(breakpoints) != len(expected) {
+		t.Fatalf("expected %d breakpoints, found %+v", len(expected), breakpoints)
+	}
+	for i, bp := range breakpoints {
+		if bp.Lambda != expected[i].lambda || bp.Flow != expected[i].flow || !reflect.DeepEqual(bp.Cut.SourceSide, expected[i].sourceSide) {
+			t.Errorf("expected breakpoint %d at %d with flow %d and source side %v, found %+v",
+				i, expected[i].lambda, expected[i].flow, expected[i].sourceSide, bp)
+		}
+	}
+	if graph.Outflow() != 13 || graph.Capacity(flownet.Source, 0) != 10 {
+		t.Errorf("expected the network to be left solved for lambda = 10, found flow %d", graph.Outflow())
+	}
+}
+
+func TestParametricMaxFlow_Exact(t *testing.T) {
+	tests := []struct {
+		name           string
+		hi             int64
+		source, sink   flownet.LinearCapacity
+		lambda, flow   int64
+		numBreakpoints int
+	}{
+		// the capacities are equal at 2.5, so the cut changes at 3.
+		{"fractional", 10, flownet.LinearCapacity{Slope: 3}, flownet.LinearCapacity{Base: 10, Slope: -1}, 3, 7, 2},
+		// the capacities are equal at 2.5e14, far beyond any range which could be searched one value at a time.
+		{"wide", 1e15, flownet.LinearCapacity{Slope: 3}, flownet.LinearCapacity{Base: 1e15, Slope: -1}, 250000000000001, 749999999999999, 2},
+		// the capacities are equal at 2, where the source side is still smallest.
+		{"integer", 6, flownet.LinearCapacity{Slope: 2}, flownet.LinearCapacity{Base: 6, Slope: -1}, 3, 3, 2},
+		{"never", 10, flownet.LinearCapacity{Slope: 1}, flownet.LinearCapacity{Base: 20}, 0, 0, 1},
+	}
+	for _, test := range tests {
+		graph := flownet.NewFlowNetwork(1)
+		breakpoints, err := graph.ParametricMaxFlow(0, test.hi,
+			map[int]flownet.LinearCapacity{0: test.source}, map[int]flownet.LinearCapacity{0: test.sink})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", test.name, err)
+		}
+		if len(breakpoints) != test.numBreakpoints {
+			t.Fatalf("%s: expected %d breakpoints, found %+v", test.name, test.numBreakpoints, breakpoints)
+		}
+		if last := breakpoints[len(breakpoints)-1]; last.Lambda != test.lambda || last.Flow != test.flow {
+			t.Errorf("%s: expected the last breakpoint at %d with flow %d, found %+v", test.name, test.lambda, test.flow, last)
+		}
+	}
+}
+
+func TestParametricMaxFlow_Instances(t *testing.T) {
+	const hi = 30
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		// every node is given capacities from the source and to the sink which depend on its ID.
+		source, sink := make(map[int]flownet.LinearCapacity), make(map[int]flownet.LinearCapacity)
+		for u := 0; u < instance.numNodes; u++ {
+			source[u] = flownet.LinearCapacity{Base: int64(u % 4), Slope: int64(u % 3)}
+			sink[u] = flownet.LinearCapacity{Base: int64(40 + 7*u%30), Slope: -int64(u % 2)}
+		}
+		graph := parametricNetwork(instance)
+		breakpoints, err := graph.ParametricMaxFlow(0, hi, source, sink)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+
+		// solving from scratch for every value of lambda, the cut must change exactly at each breakpoint.
+		var expected []flownet.Breakpoint
+		for lambda := int64(0); lambda <= hi; lambda++ {
+			fresh := parametricNetwork(instance)
+			for u := 0; u < instance.numNodes; u++ {
+				fresh.AddEdge(flownet.Source, u, source[u].Base+source[u].Slope*lambda)
+				fresh.AddEdge(u, flownet.Sink, sink[u].Base+sink[u].Slope*lambda)
+			}
+			fresh.Dinic()
+			cut := fresh.MinCut()
+			if last := len(expected) - 1; last < 0 || !reflect.DeepEqual(cut.SourceSide, expected[last].Cut.SourceSide) {
+				expected = append(expected, flownet.Breakpoint{Lambda: lambda, Flow: fresh.Outflow(), Cut: cut})
+			}
+		}
+		if !reflect.DeepEqual(breakpoints, expected) {
+			t.Errorf("%s: expected breakpoints %+v, found %+v", path, expected, breakpoints)
+		}
+		if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", path, err)
+		}
+		return nil
+	})
+}
+
+func TestParametricMaxFlow_Errors(t *testing.T) {
+	graph := flownet.NewFlowNetwork(1)
+	constant := map[int]flownet.LinearCapacity{0: {Base: 1}}
+	if _, err := graph.ParametricMaxFlow(1, 0, constant, nil); err == nil {
+		t.Errorf("expected an error for an empty range")
+	}
+	negative := map[int]flownet.LinearCapacity{0: {Slope: 1}}
+	if _, err := graph.ParametricMaxFlow(-1, 1, negative, nil); err == nil {
+		t.Errorf("expected an error for a negative capacity")
+	}
+	if _, err := graph.ParametricMaxFlow(0, 1, map[int]flownet.LinearCapacity{1: {Base: 1}}, nil); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+	if _, err := graph.ParametricMaxFlow(0, 1, map[int]flownet.LinearCapacity{0: {Base: 5, Slope: -1}}, nil); err == nil {
+		t.Errorf("expected an error for a decreasing source capacity")
+	}
+	if _, err := graph.ParametricMaxFlow(0, 1, nil, map[int]flownet.LinearCapacity{0: {Slope: 1}}); err == nil {
+		t.Errorf("expected an error for an increasing sink capacity")
+	}
+}
+
+// parametricNetwork returns a network with the edges of the provided instance which join two of its nodes,
+// and in which node 0 has a node capacity.
+func parametricNetwork(instance TestInstance) flownet.FlowNetwork {
+	result := flownet.NewFlowNetwork(instance.numNodes)
+	for e, capacity := range instance.capacities {
+		if e.from >= 0 && e.to >= 0 {
+			result.AddEdge(e.from, e.to, capacity)
+		}
+	}
+	result.SetNodeCapacity(0, 25)
+	return result
+}
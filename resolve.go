@@ -0,0 +1,135 @@
+package flownet
+
+// Resolve finds a maximum flow starting from the flow found by the last solve, rather than from scratch.
+// This is typically much faster than solving again when only a few capacities have changed. Any edge whose
+// capacity has dropped below its flow is first brought back within its capacity, by removing the excess
+// flow along paths through the network. The resulting flow is then augmented via Dinic's algorithm until
+// it is maximum.
+//
+// If no flow has been found yet, Resolve behaves like Dinic. Errors are returned as by Dinic, in which case
+// no flow is found.
+func (g *FlowNetwork) Resolve() error {
+	if err := g.resolve(); err != nil {
+		g.clearFlow()
+		return err
+	}
+	return nil
+}
+
+// resolve performs Resolve, returning any error found while preparing the network.
+func (g *FlowNetwork) resolve() error {
+	if err := g.prepare(); err != nil {
+		return err
+	}
+	if !g.repairFlow() {
+		g.clearFlow()
+	}
+	g.dinic()
+	return nil
+}
+
+// repairFlow reduces the flow along each edge whose flow exceeds its capacity, and then restores flow
+// conservation by cancelling flow along paths leading into nodes with surplus inflow, and leading out of
+// nodes with surplus outflow. Returns false if the flow could not be repaired, which only happens if the
+// flow in the network was not a valid flow before any capacities were changed.
+func (g *FlowNetwork) repairFlow() bool {
+	for u := range g.excess {
+		g.excess[u] = 0
+		g.label[u] = 0
+		g.seen[u] = 0
+	}
+	for e, flow := range g.preflow {
+		if overflow := flow - g.effectiveCapacity(e); overflow > 0 {
+			g.preflow[e] -= overflow
+			g.excess[e.from] += overflow
+			g.excess[e.to] -= overflow
+		}
+	}
+	g.excess[sourceID], g.excess[sinkID] = 0, 0
+	for u := 2; u < g.numInternalNodes(); u++ {
+		for g.excess[u] > 0 {
+			if !g.cancelFlow(u, false) {
+				return false
+			}
+		}
+	}
+	for u := 2; u < g.numInternalNodes(); u++ {
+		for g.excess[u] < 0 {
+			if !g.cancelFlow(u, true) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// cancelFlow finds a shortest path of edges carrying flow which leads into nodeID from the source or any
+// node with surplus outflow, and cancels as much flow along it as possible. If forward is true, the path
+// instead leads out of nodeID toward the sink or any node with surplus inflow. Returns false if no such
+// path exists.
+func (g *FlowNetwork) cancelFlow(nodeID int, forward bool) bool {
+	flowAlong := func(u, v int) int64 { // flow along the edge joining u to v in the direction of the search.
+		if forward {
+			return g.preflow[edge{u, v}]
+		}
+		return g.preflow[edge{v, u}]
+	}
+	isEnd := func(u int) bool {
+		if forward {
+			return u == sinkID || g.excess[u] > 0
+		}
+		return u == sourceID || g.excess[u] < 0
+	}
+	parent := make(map[int]int)
+	parent[nodeID] = nodeID
+	frontier := []int{nodeID}
+	end := -1
+	for len(frontier) > 0 && end == -1 {
+		u := frontier[0]
+		frontier = frontier[1:]
+		for _, v := range g.adjacencyVisitList[u] {
+			if _, ok := parent[v]; ok || flowAlong(u, v) <= 0 {
+				continue
+			}
+			parent[v] = u
+			if isEnd(v) {
+				end = v
+				break
+			}
+			frontier = append(frontier, v)
+		}
+	}
+	if end == -1 {
+		return false
+	}
+	amount := abs64(g.excess[nodeID])
+	if end != sourceID && end != sinkID {
+		amount = min64(amount, abs64(g.excess[end]))
+	}
+	for v := end; v != nodeID; v = parent[v] {
+		amount = min64(amount, flowAlong(parent[v], v))
+	}
+	for v := end; v != nodeID; v = parent[v] {
+		if forward {
+			g.preflow[edge{parent[v], v}] -= amount
+		} else {
+			g.preflow[edge{v, parent[v]}] -= amount
+		}
+	}
+	if forward {
+		g.excess[nodeID] += amount
+		g.excess[end] -= amount
+	} else {
+		g.excess[nodeID] -= amount
+		g.excess[end] += amount
+	}
+	g.excess[sourceID], g.excess[sinkID] = 0, 0
+	return true
+}
+
+func abs64(x int64) int64 {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,76 @@
+package flownet_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestResolveRandomNetworks(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for idx := 0; idx < 50; idx++ {
+		numNodes := 2 + r.Intn(30)
+		resolved := flownet.NewFlowNetwork(numNodes)
+		fresh := flownet.NewFlowNetwork(numNodes)
+		type edge struct {
+			from, to int
+			id       flownet.EdgeID
+		}
+		var edges []edge
+		for i := 0; i < 3*numNodes; i++ {
+			from, to := r.Intn(numNodes), r.Intn(numNodes)
+			if from == to {
+				continue
+			}
+			cap := r.Int63n(20)
+			id, _ := resolved.AddEdge(from, to, cap)
+			edges = append(edges, edge{from, to, id})
+		}
+		resolved.PushRelabel()
+		for i := 0; i < 5; i++ {
+			// change a few capacities; some will drop below the flow along their edge.
+			for j := 0; j < 3 && len(edges) > 0; j++ {
+				e := edges[r.Intn(len(edges))]
+				resolved.SetCapacity(e.id, r.Int63n(20))
+			}
+			resolved.Resolve()
+
+			fresh = flownet.NewFlowNetwork(numNodes)
+			for _, e := range edges {
+				fresh.AddEdge(e.from, e.to, resolved.CapacityByID(e.id))
+			}
+			fresh.Dinic()
+			if resolved.Outflow() != fresh.Outflow() {
+				t.Errorf("test #%d, change #%d: Resolve found max flow of %d but solving from scratch found %d", idx, i, resolved.Outflow(), fresh.Outflow())
+			}
+			if err := flownet.SanityChecks.FlowNetwork(resolved, true); err != nil {
+				t.Errorf("test #%d, change #%d: sanity checks failed: %v", idx, i, err)
+			}
+		}
+	}
+}
+
+func TestResolve_ManualSource(t *testing.T) {
+	g := flownet.NewFlowNetwork(3)
+	source, _ := g.AddEdge(flownet.Source, 0, 10)
+	g.AddEdge(0, 1, 10)
+	g.AddEdge(0, 2, 10)
+	g.Dinic()
+	if g.Outflow() != 10 {
+		t.Errorf("expected max flow of 10, found %d", g.Outflow())
+	}
+	g.SetCapacity(source, 4)
+	g.Resolve()
+	if g.Outflow() != 4 {
+		t.Errorf("expected max flow of 4 after lowering the source capacity, found %d", g.Outflow())
+	}
+	if err := flownet.SanityChecks.FlowNetwork(g, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+	g.SetCapacity(source, 20)
+	g.Resolve()
+	if g.Outflow() != 20 {
+		t.Errorf("expected max flow of 20 after raising the source capacity, found %d", g.Outflow())
+	}
+}
@@ -41,20 +41,8 @@ func (sc sanityCheckers) FlowNetwork(fn FlowNetwork, flowEquality bool) error {
 // augmentingPathCheck returns an error if any augmenting path is found in the residual flow network.
 func (sanityCheckers) augmentingPathCheck(fn FlowNetwork) error {
 	// run a BFS from source to sink using the residual flow network, if you find a path, it's wrong.
-	frontier := []int{sourceID}
-	visited := make(map[int]struct{})
-	for len(frontier) > 0 {
-		curr := frontier[0]
-		frontier = frontier[1:]
-		visited[curr] = struct{}{}
-		for i := 0; i < fn.numNodes+2; i++ {
-			if _, ok := visited[i]; !ok && fn.residual(edge{curr, i}) > 0 {
-				if i == sinkID {
-					return fmt.Errorf("found an augmenting path from source to sink via edge %d; flow is not maximum", curr)
-				}
-				frontier = append(frontier, i)
-			}
-		}
+	if fn.residualReachable(sourceID)[sinkID] {
+		return fmt.Errorf("found an augmenting path from source to sink; flow is not maximum")
 	}
 	return nil
 }
@@ -0,0 +1,117 @@
+package flownet
+
+import "sort"
+
+// MinCutMembership describes whether an edge crosses the minimum cuts of a network.
+type MinCutMembership int
+
+const (
+	// InNoMinCut edges cross no minimum cut; small changes to their capacity do not affect the maximum flow.
+	InNoMinCut MinCutMembership = iota
+	// InSomeMinCut edges cross some minimum cuts, but not all of them. Reducing their capacity reduces the
+	// maximum flow, but increasing it does not increase the maximum flow.
+	InSomeMinCut
+	// InEveryMinCut edges cross every minimum cut. Any change to their capacity changes the maximum flow.
+	InEveryMinCut
+)
+
+// String returns the name of the membership.
+func (m MinCutMembership) String() string {
+	switch m {
+	case InSomeMinCut:
+		return "InSomeMinCut"
+	case InEveryMinCut:
+		return "InEveryMinCut"
+	}
+	return "InNoMinCut"
+}
+
+// EdgeSensitivity describes how the maximum flow of a network depends on the capacity of one edge.
+type EdgeSensitivity struct {
+	// Edge is the edge described.
+	Edge Edge
+	// ID is the ID returned by AddEdge when the edge was added, or -1 if it was not added via AddEdge.
+	ID EdgeID
+	// Capacity and Flow are the capacity of the edge and the flow along it.
+	Capacity, Flow int64
+	// Membership describes whether the edge crosses the minimum cuts of the network.
+	Membership MinCutMembership
+	// MarginalGain is the amount by which the maximum flow rises if the capacity of the edge rises by one
+	// unit. It is 1 for edges in every minimum cut and 0 otherwise.
+	MarginalGain int64
+	// MarginalLoss is the amount by which the maximum flow falls if the capacity of the edge falls by one
+	// unit. It is 1 for edges in some minimum cut, and 0 otherwise or if the edge has no capacity.
+	MarginalLoss int64
+}
+
+// Sensitivity reports how the maximum flow depends on the capacity of each edge of the network, sorted by
+// From, then by To, then by ID. Parallel edges are reported separately. The report is only meaningful once
+// a maximum flow has been found.
+//
+// The report is computed from the residual network without solving again. Every minimum cut contains the
+// nodes reachable from the source, and excludes the nodes from which the sink can be reached. A saturated
+// edge crosses some minimum cut if its head cannot be reached from its tail in the residual network, and
+// neither endpoint is fixed to the wrong side of the cut. It crosses every minimum cut if its endpoints are
+// fixed to either side.
+func (g FlowNetwork) Sensitivity() []EdgeSensitivity {
+	neighbors := g.residualGraph(false)
+	reachable := reachableFrom(neighbors, sourceID)
+	reachesSink := reachableFrom(g.residualGraph(true), sinkID)
+	component, _ := stronglyConnectedComponents(neighbors)
+	// reaches reports whether v can be reached from u in the residual network. When the edge from u to v
+	// carries flow, the residual edge back from v to u means that is so iff they share a component; edges
+	// without flow need a search, which is cached for each u.
+	searched := make(map[int][]bool)
+	reaches := func(e edge) bool {
+		if component[e.from] == component[e.to] || g.preflow[e] > 0 {
+			return component[e.from] == component[e.to]
+		}
+		if _, ok := searched[e.from]; !ok {
+			searched[e.from] = reachableFrom(neighbors, e.from)
+		}
+		return searched[e.from][e.to]
+	}
+	var result []EdgeSensitivity
+	for e, capacity := range g.capacity {
+		visible, ok := g.visibleEdge(e)
+		if !ok {
+			continue
+		}
+		// parallel edges cross the same cuts, so their memberships are found together.
+		s := EdgeSensitivity{Edge: Edge{externalID(visible.from), externalID(visible.to)}, ID: -1}
+		switch {
+		case capacity == Infinite || reachable[sinkID] || g.residual(e) > 0:
+		case reachable[e.from] && reachesSink[e.to]:
+			s.Membership, s.MarginalGain, s.MarginalLoss = InEveryMinCut, 1, 1
+		case !reachesSink[e.from] && !reachable[e.to] && !reaches(e):
+			s.Membership, s.MarginalLoss = InSomeMinCut, 1
+		}
+		ids, ok := g.parallel[e]
+		if !ok {
+			s.Capacity, s.Flow = capacity, g.preflow[e]
+			if capacity == 0 {
+				s.MarginalLoss = 0
+			}
+			result = append(result, s)
+			continue
+		}
+		for _, id := range ids {
+			p := s
+			p.ID, p.Capacity, p.Flow = id, g.edgeCapacity[id], g.FlowByID(id)
+			if p.Capacity == 0 {
+				p.MarginalLoss = 0
+			}
+			result = append(result, p)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Edge != result[j].Edge {
+			if result[i].Edge.From != result[j].Edge.From {
+				return result[i].Edge.From < result[j].Edge.From
+			}
+			return result[i].Edge.To < result[j].Edge.To
+		}
+		return result[i].ID < result[j].ID
+	})
+	return result
+}
@@ -0,0 +1,157 @@
+package flownet_test
+
+import (
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestSensitivity(t *testing.T) {
+	// two disjoint paths; the first is limited by one edge, the second by two equal edges.
+	graph := flownet.NewFlowNetwork(4)
+	graph.AddEdge(flownet.Source, 0, 10)
+	graph.AddEdge(0, 1, 3)
+	graph.AddEdge(1, flownet.Sink, 10)
+	graph.AddEdge(flownet.Source, 2, 4)
+	graph.AddEdge(2, 3, 4)
+	graph.AddEdge(3, flownet.Sink, 10)
+	graph.Dinic()
+
+	expected := map[flownet.Edge]flownet.MinCutMembership{
+		{From: flownet.Source, To: 0}: flownet.InNoMinCut,
+		{From: flownet.Source, To: 2}: flownet.InSomeMinCut,
+		{From: 0, To: 1}:              flownet.InEveryMinCut,
+		{From: 1, To: flownet.Sink}:   flownet.InNoMinCut,
+		{From: 2, To: 3}:              flownet.InSomeMinCut,
+		{From: 3, To: flownet.Sink}:   flownet.InNoMinCut,
+	}
+	report := graph.Sensitivity()
+	if len(report) != len(expected) {
+		t.Fatalf("expected %d edges in the report, found %+v", len(expected), report)
+	}
+	for _, s := range report {
+		if s.Membership != expected[s.Edge] {
+			t.Errorf("expected edge %v to be %v, found %v", s.Edge, expected[s.Edge], s.Membership)
+		}
+		if s.Flow != graph.Flow(s.Edge.From, s.Edge.To) || s.Capacity != graph.Capacity(s.Edge.From, s.Edge.To) {
+			t.Errorf("unexpected flow or capacity for edge %v: %+v", s.Edge, s)
+		}
+	}
+	if report[0].Edge != (flownet.Edge{From: flownet.Source, To: 0}) || report[0].ID != 0 {
+		t.Errorf("expected the report to begin with edge 0, found %+v", report[0])
+	}
+}
+
+func TestSensitivity_ZeroCapacity(t *testing.T) {
+	// node 3 can be reached from node 0 through node 2 in the residual network, so no minimum cut can
+	// separate them along the edge from 0 to 3, even though it has no capacity.
+	graph := flownet.NewFlowNetwork(4)
+	graph.AddEdge(flownet.Source, 0, 1)
+	graph.AddEdge(0, 1, 1)
+	graph.AddEdge(1, flownet.Sink, 1)
+	graph.AddEdge(0, 2, 5)
+	graph.AddEdge(2, 3, 5)
+	closed, _ := graph.AddEdge(0, 3, 0)
+	graph.Dinic()
+	for _, s := range graph.Sensitivity() {
+		if s.ID == closed && s.Membership != flownet.InNoMinCut {
+			t.Errorf("expected edge %v to be InNoMinCut, found %v", s.Edge, s.Membership)
+		}
+	}
+}
+
+func TestSensitivity_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		// close every seventh edge, so that edges without capacity are covered too.
+		capacities := make(map[Edge]int64, len(instance.capacities))
+		for e, capacity := range instance.capacities {
+			if (e.from+e.to)%7 == 0 {
+				capacity = 0
+			}
+			capacities[e] = capacity
+		}
+		graph := instanceNetwork(capacities, instance.numNodes)
+		graph.Dinic()
+		maxFlow := graph.Outflow()
+
+		// every marginal value must agree with solving again after changing the capacity, and every
+		// membership with solving again after forcing the edge to cross the cut. Large instances are
+		// sampled, since each edge takes several solves.
+		report := graph.Sensitivity()
+		for i, s := range report {
+			if i%(len(report)/100+1) != 0 {
+				continue
+			}
+			graph.SetCapacity(s.ID, s.Capacity+1)
+			graph.Dinic()
+			if gain := graph.Outflow() - maxFlow; gain != s.MarginalGain {
+				t.Errorf("%s: expected a gain of %d for edge %v, found %d", path, gain, s.Edge, s.MarginalGain)
+			}
+			if s.Capacity > 0 {
+				graph.SetCapacity(s.ID, s.Capacity-1)
+				graph.Dinic()
+				if loss := maxFlow - graph.Outflow(); loss != s.MarginalLoss {
+					t.Errorf("%s: expected a loss of %d for edge %v, found %d", path, loss, s.Edge, s.MarginalLoss)
+				}
+			}
+			graph.SetCapacity(s.ID, s.Capacity)
+
+			expected := flownet.InNoMinCut
+			if s.MarginalGain > 0 {
+				expected = flownet.InEveryMinCut
+			} else if crossesMinCut(capacities, instance.numNodes, s.Edge, maxFlow) {
+				expected = flownet.InSomeMinCut
+			}
+			if s.Membership != expected {
+				t.Errorf("%s: expected edge %v to be %v, found %v", path, s.Edge, expected, s.Membership)
+			}
+		}
+		return nil
+	})
+}
+
+// instanceNetwork builds a flow network with the provided capacities. Unless the capacities already include
+// edges leaving the source or entering the sink, each node without incoming edges is joined to the source,
+// and each node without outgoing edges to the sink, by an edge too large to cut.
+func instanceNetwork(capacities map[Edge]int64, numNodes int) flownet.FlowNetwork {
+	uncuttable, manual := int64(1), false
+	hasIn, hasOut := make([]bool, numNodes), make([]bool, numNodes)
+	graph := flownet.NewFlowNetwork(numNodes)
+	for e, capacity := range capacities {
+		graph.AddEdge(e.from, e.to, capacity)
+		uncuttable += capacity
+		if e.from < 0 || e.to < 0 {
+			manual = true
+			continue
+		}
+		hasOut[e.from], hasIn[e.to] = true, true
+	}
+	for u := 0; u < numNodes && !manual; u++ {
+		if hasOut[u] && !hasIn[u] {
+			graph.AddEdge(flownet.Source, u, uncuttable)
+		}
+		if hasIn[u] && !hasOut[u] {
+			graph.AddEdge(u, flownet.Sink, uncuttable)
+		}
+	}
+	return graph
+}
+
+// crossesMinCut reports whether some minimum cut of the network built by instanceNetwork separates the
+// endpoints of e. The tail of e is joined to the source and its head to the sink by edges too large to cut,
+// which leaves the maximum flow unchanged iff such a cut exists.
+func crossesMinCut(capacities map[Edge]int64, numNodes int, e flownet.Edge, maxFlow int64) bool {
+	uncuttable := int64(1)
+	for _, capacity := range capacities {
+		uncuttable += capacity
+	}
+	graph := instanceNetwork(capacities, numNodes)
+	if e.From != flownet.Source {
+		graph.AddEdge(flownet.Source, e.From, uncuttable)
+	}
+	if e.To != flownet.Sink {
+		graph.AddEdge(e.To, flownet.Sink, uncuttable)
+	}
+	graph.Dinic()
+	return graph.Outflow() == maxFlow
+}
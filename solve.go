@@ -0,0 +1,96 @@
+package flownet
+
+import "context"
+
+// SolveOptions configures a call to SolveContext.
+type SolveOptions struct {
+	// Progress, if non-nil, is called periodically while the flow is being found, and once more when
+	// the flow is complete.
+	Progress func(Progress)
+}
+
+// Progress describes how much work remains and how much has been done while finding a flow.
+type Progress struct {
+	// Excess is the total excess flow held by nodes other than the source and sink. It reaches zero
+	// once the flow has been found.
+	Excess int64
+	// Relabels is the number of relabel operations performed so far.
+	Relabels int
+}
+
+// SolveContext finds a maximum flow via the same algorithm as PushRelabel. The context is checked before
+// any work is done, once the network has been prepared, and periodically while excess is discharged from
+// each node; if it is cancelled or its deadline passes, SolveContext stops and returns ctx.Err(). The flow
+// in the network is not meaningful in that case. An error wrapping ErrCapacityOverflow is returned if the
+// capacities are too large to be combined without overflowing, and ErrUnboundedFlow is returned if a path
+// of Infinite edges joins the source to the sink; no flow is found in either case. Otherwise, any error
+// returned is a *RelabelError.
+func (g *FlowNetwork) SolveContext(ctx context.Context, opts SolveOptions) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return g.pushRelabel(newMonitor(ctx, opts, g))
+}
+
+// monitor keeps track of the work performed by push-relabel, checking for cancellation and reporting
+// progress as it goes.
+type monitor struct {
+	ctx      context.Context
+	progress func(Progress)
+	g        *FlowNetwork
+	// interval is the number of steps between progress reports.
+	interval int
+	steps    int
+	relabels int
+}
+
+// checkInterval is the number of steps between checks for cancellation.
+const checkInterval = 1 << 10
+
+func newMonitor(ctx context.Context, opts SolveOptions, g *FlowNetwork) *monitor {
+	interval := g.numNodes
+	if interval < checkInterval {
+		interval = checkInterval
+	}
+	return &monitor{ctx: ctx, progress: opts.Progress, g: g, interval: interval}
+}
+
+// step records a single push or relabel, returning ctx.Err() if the context is done.
+func (m *monitor) step() error {
+	m.steps++
+	if m.progress != nil && m.steps%m.interval == 0 {
+		m.report()
+	}
+	if m.steps%checkInterval != 0 {
+		return nil
+	}
+	select {
+	case <-m.ctx.Done():
+		return m.ctx.Err()
+	default:
+		return nil
+	}
+}
+
+// relabeled records a single relabel operation.
+func (m *monitor) relabeled() {
+	m.relabels++
+}
+
+// done reports the final progress.
+func (m *monitor) done() {
+	if m.progress != nil {
+		m.report()
+	}
+}
+
+// report calls the progress callback with the current state of the network.
+func (m *monitor) report() {
+	excess := int64(0)
+	for u := 2; u < m.g.numInternalNodes(); u++ {
+		if m.g.excess[u] > 0 {
+			excess += m.g.excess[u]
+		}
+	}
+	m.progress(Progress{Excess: excess, Relabels: m.relabels})
+}
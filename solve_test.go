@@ -0,0 +1,99 @@
+package flownet_test
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestSolveContext_Cancelled(t *testing.T) {
+	graph := randomNetwork(rand.New(rand.NewSource(1)), 2000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := graph.SolveContext(ctx, flownet.SolveOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, found %v", err)
+	}
+}
+
+func TestSolveContext_CancelledSmall(t *testing.T) {
+	// a network this small is solved in fewer steps than the periodic check needs, so the context must be
+	// checked before any work is done.
+	graph := flownet.NewFlowNetwork(3)
+	graph.AddEdge(0, 1, 3)
+	graph.AddEdge(1, 2, 3)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := graph.SolveContext(ctx, flownet.SolveOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, found %v", err)
+	}
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 5, 0)
+	c.AddEdge(1, 2, 5, 0)
+	c.SetNodeDemand(0, -3)
+	c.SetNodeDemand(2, 3)
+	if err := c.SolveContext(ctx, flownet.SolveOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled from a circulation, found %v", err)
+	}
+}
+
+func TestSolveContext_Progress(t *testing.T) {
+	graph := randomNetwork(rand.New(rand.NewSource(1)), 2000)
+	var reports []flownet.Progress
+	err := graph.SolveContext(context.Background(), flownet.SolveOptions{
+		Progress: func(p flownet.Progress) { reports = append(reports, p) },
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(reports) < 2 {
+		t.Fatalf("expected several progress reports, found %d", len(reports))
+	}
+	last := reports[len(reports)-1]
+	if last.Excess != 0 {
+		t.Errorf("expected no excess to remain in the final report, found %d", last.Excess)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Relabels < reports[i-1].Relabels {
+			t.Errorf("report #%d: relabel count decreased from %d to %d", i, reports[i-1].Relabels, reports[i].Relabels)
+		}
+	}
+	if err := flownet.SanityChecks.FlowNetwork(graph, true); err != nil {
+		t.Errorf("sanity checks failed: %v", err)
+	}
+}
+
+func TestSolveContext_Circulation(t *testing.T) {
+	c := flownet.NewCirculation(3)
+	c.AddEdge(0, 1, 5, 0)
+	c.AddEdge(1, 2, 5, 0)
+	c.SetNodeDemand(0, -3)
+	c.SetNodeDemand(2, 3)
+	if err := c.SolveContext(context.Background(), flownet.SolveOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !c.SatisfiesDemand() {
+		t.Errorf("expected demand to be satisfied")
+	}
+}
+
+// randomNetwork constructs a random flow network with the provided number of nodes.
+func randomNetwork(r *rand.Rand, numNodes int) flownet.FlowNetwork {
+	result := flownet.NewFlowNetwork(numNodes)
+	ids := make(map[[2]int]flownet.EdgeID)
+	for i := 0; i < 4*numNodes; i++ {
+		from, to := r.Intn(numNodes), r.Intn(numNodes)
+		if from == to {
+			continue
+		}
+		// edges drawn twice have their capacity replaced, so the network has no parallel edges.
+		if id, ok := ids[[2]int{from, to}]; ok {
+			result.SetCapacity(id, r.Int63n(100))
+		} else {
+			ids[[2]int{from, to}], _ = result.AddEdge(from, to, r.Int63n(100))
+		}
+	}
+	return result
+}
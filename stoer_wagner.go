@@ -0,0 +1,124 @@
+package flownet
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// GlobalMinCut finds a minimum cut of the graph via the Stoer-Wagner algorithm. Unlike a minimum s-t cut,
+// a global minimum cut may separate any pair of nodes; it is the cheapest way to split the graph into two
+// non-empty parts. If the graph is disconnected, the cut found has zero capacity. An error is returned if
+// the graph has fewer than two nodes.
+//
+// Each phase of the algorithm adds nodes to a growing set in order of how tightly they are connected to it.
+// The last node added is cut from the rest at the cost of its connection to the set, and is then merged
+// with the node added just before it. The cheapest cut found in any phase is a global minimum cut.
+func (g UndirectedGraph) GlobalMinCut() (UndirectedCut, error) {
+	n := g.numNodes
+	if n < 2 {
+		return UndirectedCut{}, fmt.Errorf("a cut requires at least two nodes, found %d", n)
+	}
+	// adjacency stores the total capacity between each pair of merged nodes.
+	adjacency := make([]map[int]int64, n)
+	// members stores the nodes of the original graph which have been merged into each node.
+	members := make([][]int, n)
+	alive := make([]int, n)
+	for u := 0; u < n; u++ {
+		adjacency[u] = make(map[int]int64)
+		members[u] = []int{u}
+		alive[u] = u
+	}
+	for e, capacity := range g.capacity {
+		if capacity > 0 {
+			adjacency[e.from][e.to] = capacity
+			adjacency[e.to][e.from] = capacity
+		}
+	}
+
+	var best []int
+	bestCapacity := int64(-1)
+	weight := make([]int64, n)
+	added := make([]bool, n)
+	for len(alive) > 1 {
+		h := &adjacencyHeap{}
+		for _, u := range alive {
+			weight[u], added[u] = 0, false
+			h.entries = append(h.entries, adjacencyEntry{u, 0})
+		}
+		heap.Init(h)
+		prev, last := -1, -1
+		for i := 0; i < len(alive); {
+			entry := heap.Pop(h).(adjacencyEntry)
+			if added[entry.nodeID] || entry.weight != weight[entry.nodeID] {
+				continue // stale entry
+			}
+			u := entry.nodeID
+			added[u] = true
+			prev, last = last, u
+			for v, capacity := range adjacency[u] {
+				if !added[v] {
+					weight[v], _ = addCapacity(weight[v], capacity)
+					heap.Push(h, adjacencyEntry{v, weight[v]})
+				}
+			}
+			i++
+		}
+		if bestCapacity < 0 || weight[last] < bestCapacity {
+			bestCapacity = weight[last]
+			best = append(best[:0], members[last]...)
+		}
+		// merge last into prev.
+		for v, capacity := range adjacency[last] {
+			delete(adjacency[v], last)
+			if v == prev {
+				continue
+			}
+			adjacency[prev][v], _ = addCapacity(adjacency[prev][v], capacity)
+			adjacency[v][prev] = adjacency[prev][v]
+		}
+		adjacency[last] = nil
+		members[prev] = append(members[prev], members[last]...)
+		for i, u := range alive {
+			if u == last {
+				alive = append(alive[:i], alive[i+1:]...)
+				break
+			}
+		}
+	}
+	inBest := make([]bool, n)
+	for _, u := range best {
+		inBest[u] = true
+	}
+	return g.cut(func(u int) bool { return inBest[u] }), nil
+}
+
+// adjacencyEntry records the weight of a node's connection to the set of nodes added in a phase of
+// the Stoer-Wagner algorithm.
+type adjacencyEntry struct {
+	nodeID int
+	weight int64
+}
+
+// adjacencyHeap is a max-heap of adjacencyEntries, with ties broken by node ID.
+type adjacencyHeap struct {
+	entries []adjacencyEntry
+}
+
+func (h adjacencyHeap) Len() int { return len(h.entries) }
+func (h adjacencyHeap) Less(i, j int) bool {
+	if h.entries[i].weight != h.entries[j].weight {
+		return h.entries[i].weight > h.entries[j].weight
+	}
+	return h.entries[i].nodeID < h.entries[j].nodeID
+}
+func (h adjacencyHeap) Swap(i, j int) { h.entries[i], h.entries[j] = h.entries[j], h.entries[i] }
+
+func (h *adjacencyHeap) Push(x interface{}) {
+	h.entries = append(h.entries, x.(adjacencyEntry))
+}
+
+func (h *adjacencyHeap) Pop() interface{} {
+	result := h.entries[len(h.entries)-1]
+	h.entries = h.entries[:len(h.entries)-1]
+	return result
+}
@@ -0,0 +1,78 @@
+package flownet_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestGlobalMinCut_BruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for trial := 0; trial < 50; trial++ {
+		n := 2 + r.Intn(9)
+		graph := randomUndirectedGraph(r, n)
+		cut, err := graph.GlobalMinCut()
+		if err != nil {
+			t.Fatalf("trial %d: unexpected error: %v", trial, err)
+		}
+		if len(cut.SideA) == 0 || len(cut.SideB) == 0 || len(cut.SideA)+len(cut.SideB) != n {
+			t.Fatalf("trial %d: cut %v does not split %d nodes into two non-empty sides", trial, cut, n)
+		}
+		if cutCapacity(graph, cut) != cut.Capacity {
+			t.Errorf("trial %d: partition has capacity %d, not %d", trial, cutCapacity(graph, cut), cut.Capacity)
+		}
+		// every partition of the nodes can be described by a bitmask in which node n-1 is on side B.
+		best := int64(-1)
+		for mask := 1; mask < 1<<(n-1); mask++ {
+			var partition flownet.UndirectedCut
+			for u := 0; u < n; u++ {
+				if mask&(1<<u) != 0 {
+					partition.SideA = append(partition.SideA, u)
+				} else {
+					partition.SideB = append(partition.SideB, u)
+				}
+			}
+			if capacity := cutCapacity(graph, partition); best < 0 || capacity < best {
+				best = capacity
+			}
+		}
+		if cut.Capacity != best {
+			t.Errorf("trial %d: expected global min cut of %d, found %d", trial, best, cut.Capacity)
+		}
+	}
+}
+
+func TestGlobalMinCut(t *testing.T) {
+	graph := flownet.NewUndirectedGraph(4)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(1, 2, 1)
+	graph.AddEdge(2, 3, 5)
+	graph.AddEdge(0, 3, 2)
+	cut, err := graph.GlobalMinCut()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cut.Capacity != 3 {
+		t.Errorf("expected a cut of capacity 3, found %d", cut.Capacity)
+	}
+	if len(cut.SideA) != 2 || !(containsNode(cut.SideA, 0) == containsNode(cut.SideA, 1)) {
+		t.Errorf("expected nodes 0 and 1 to be separated from nodes 2 and 3, found %v", cut)
+	}
+}
+
+func TestGlobalMinCut_Disconnected(t *testing.T) {
+	graph := flownet.NewUndirectedGraph(4)
+	graph.AddEdge(0, 1, 5)
+	graph.AddEdge(2, 3, 5)
+	cut, err := graph.GlobalMinCut()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cut.Capacity != 0 {
+		t.Errorf("expected a cut of capacity 0, found %d", cut.Capacity)
+	}
+	if _, err := flownet.NewUndirectedGraph(1).GlobalMinCut(); err == nil {
+		t.Errorf("expected an error for a graph with one node")
+	}
+}
@@ -0,0 +1,172 @@
+package flownet
+
+import "fmt"
+
+// A Transportation problem asks for the cheapest way to ship goods from suppliers to customers. Each
+// supplier can ship up to its supply, and each customer must receive exactly its demand. Goods travel along
+// lanes which join each supplier to each customer, and each unit shipped along a lane has a cost. Lanes may
+// be given a capacity, or forbidden altogether.
+//
+// Suppliers and customers are numbered separately, from zero, in the order of the supply and demand vectors.
+type Transportation struct {
+	supply, demand []int64
+	// cost stores the cost per unit shipped along each lane, indexed by supplier and then by customer.
+	cost [][]int64
+	// capacity stores the capacity of each lane which has one, keyed by supplier and customer.
+	capacity map[Edge]int64
+	// forbidden stores each lane along which nothing may be shipped, keyed by supplier and customer.
+	forbidden map[Edge]bool
+	// shipments stores the amount shipped along each lane by the last call to Solve.
+	shipments [][]int64
+	// totalCost stores the total cost of the shipments found by the last call to Solve.
+	totalCost int64
+}
+
+// NewTransportation constructs a transportation problem with the provided supplies and demands, in which
+// cost[i][j] is the cost of shipping one unit from supplier i to customer j. Costs may be negative. An error
+// is returned if any supply or demand is negative, or if cost does not have a row for each supplier and a
+// column for each customer.
+func NewTransportation(supply, demand []int64, cost [][]int64) (Transportation, error) {
+	for i, s := range supply {
+		if s < 0 {
+			return Transportation{}, fmt.Errorf("supplies must be non-negative; supplier %d has supply %d", i, s)
+		}
+	}
+	for j, d := range demand {
+		if d < 0 {
+			return Transportation{}, fmt.Errorf("demands must be non-negative; customer %d has demand %d", j, d)
+		}
+	}
+	if len(cost) != len(supply) {
+		return Transportation{}, fmt.Errorf("expected a row of costs for each of %d suppliers, found %d rows", len(supply), len(cost))
+	}
+	for i, row := range cost {
+		if len(row) != len(demand) {
+			return Transportation{}, fmt.Errorf("expected a cost for each of %d customers, found %d for supplier %d", len(demand), len(row), i)
+		}
+	}
+	costs := make([][]int64, len(cost))
+	for i, row := range cost {
+		costs[i] = append([]int64{}, row...)
+	}
+	return Transportation{
+		supply:    append([]int64{}, supply...),
+		demand:    append([]int64{}, demand...),
+		cost:      costs,
+		capacity:  make(map[Edge]int64),
+		forbidden: make(map[Edge]bool),
+	}, nil
+}
+
+// SetLaneCapacity limits the amount which may be shipped from the provided supplier to the provided
+// customer. Use Infinite to remove the limit. An error is returned if either ID is not valid, or if the
+// capacity is negative.
+func (t *Transportation) SetLaneCapacity(supplier, customer int, capacity int64) error {
+	if err := t.checkLane(supplier, customer); err != nil {
+		return err
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacities must be non-negative")
+	}
+	if capacity == Infinite {
+		delete(t.capacity, Edge{supplier, customer})
+	} else {
+		t.capacity[Edge{supplier, customer}] = capacity
+	}
+	return nil
+}
+
+// ForbidLane prevents anything from being shipped from the provided supplier to the provided customer, or
+// allows it once more if forbidden is false. An error is returned if either ID is not valid.
+func (t *Transportation) ForbidLane(supplier, customer int, forbidden bool) error {
+	if err := t.checkLane(supplier, customer); err != nil {
+		return err
+	}
+	if forbidden {
+		t.forbidden[Edge{supplier, customer}] = true
+	} else {
+		delete(t.forbidden, Edge{supplier, customer})
+	}
+	return nil
+}
+
+// checkLane returns an error if no lane joins the provided supplier and customer.
+func (t *Transportation) checkLane(supplier, customer int) error {
+	if supplier < 0 || supplier >= len(t.supply) {
+		return fmt.Errorf("no supplier with ID %d is known", supplier)
+	}
+	if customer < 0 || customer >= len(t.demand) {
+		return fmt.Errorf("no customer with ID %d is known", customer)
+	}
+	return nil
+}
+
+// Solve finds the cheapest shipments which meet every demand, via MinCostFlow. Suppliers and customers
+// become nodes of the network; the source supplies each supplier, each lane which is not forbidden becomes
+// an edge, and each customer sends its demand to the sink.
+//
+// An error wrapping ErrInfeasible is returned if the demands cannot all be met; the shipments found are then
+// the cheapest among those which meet as much demand as possible. An error wrapping ErrCapacityOverflow is
+// returned if the total demand is too large, or if the total cost of the shipments found cannot be stored in
+// an int64; the shipments are then kept, but Cost returns zero.
+func (t *Transportation) Solve() error {
+	numSuppliers := len(t.supply)
+	m := NewMinCostFlow(numSuppliers + len(t.demand))
+	lanes := make(map[Edge]EdgeID)
+	// supplies, demands, and capacities were all checked when they were set, so no edge is rejected.
+	for i, supply := range t.supply {
+		m.AddEdge(Source, i, supply, 0)
+	}
+	required := int64(0)
+	for j, demand := range t.demand {
+		m.AddEdge(numSuppliers+j, Sink, demand, 0)
+		var ok bool
+		if required, ok = addCapacity(required, demand); !ok || required > maxFiniteCapacity {
+			return fmt.Errorf("%w: the total demand must be at most %d", ErrCapacityOverflow, int64(maxFiniteCapacity))
+		}
+	}
+	for i, row := range t.cost {
+		for j, cost := range row {
+			lane := Edge{i, j}
+			if t.forbidden[lane] {
+				continue
+			}
+			capacity, ok := t.capacity[lane]
+			if !ok {
+				capacity = Infinite
+			}
+			lanes[lane], _ = m.AddEdge(i, numSuppliers+j, capacity, cost)
+		}
+	}
+
+	err := m.Solve(required)
+	t.shipments = make([][]int64, numSuppliers)
+	for i := range t.shipments {
+		t.shipments[i] = make([]int64, len(t.demand))
+		for j := range t.shipments[i] {
+			if id, ok := lanes[Edge{i, j}]; ok {
+				t.shipments[i][j] = m.FlowByID(id)
+			}
+		}
+	}
+	t.totalCost = m.Cost()
+	return err
+}
+
+// Shipments returns the amount shipped from each supplier to each customer by the last call to Solve,
+// indexed by supplier and then by customer. Returns nil if Solve has not been called.
+func (t Transportation) Shipments() [][]int64 {
+	if t.shipments == nil {
+		return nil
+	}
+	result := make([][]int64, len(t.shipments))
+	for i, row := range t.shipments {
+		result[i] = append([]int64{}, row...)
+	}
+	return result
+}
+
+// Cost returns the total cost of the shipments found by the last call to Solve.
+func (t Transportation) Cost() int64 {
+	return t.totalCost
+}
@@ -0,0 +1,214 @@
+package flownet_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestTransportation(t *testing.T) {
+	// supplier 0 is cheaper for both customers, but can only cover 5 of the 8 units demanded; it saves most
+	// by serving customer 1.
+	problem, err := flownet.NewTransportation([]int64{5, 5}, []int64{4, 4}, [][]int64{{1, 3}, {2, 5}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := problem.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := [][]int64{{1, 4}, {3, 0}}; !reflect.DeepEqual(problem.Shipments(), expected) || problem.Cost() != 19 {
+		t.Errorf("expected shipments %v at cost 19, found %v at cost %d", expected, problem.Shipments(), problem.Cost())
+	}
+
+	problem.SetLaneCapacity(0, 1, 2)
+	if err := problem.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := [][]int64{{3, 2}, {1, 2}}; !reflect.DeepEqual(problem.Shipments(), expected) || problem.Cost() != 21 {
+		t.Errorf("expected shipments %v at cost 21, found %v at cost %d", expected, problem.Shipments(), problem.Cost())
+	}
+
+	problem.SetLaneCapacity(0, 1, flownet.Infinite)
+	problem.ForbidLane(0, 1, true)
+	if err := problem.Solve(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := [][]int64{{4, 0}, {0, 4}}; !reflect.DeepEqual(problem.Shipments(), expected) || problem.Cost() != 24 {
+		t.Errorf("expected shipments %v at cost 24, found %v at cost %d", expected, problem.Shipments(), problem.Cost())
+	}
+
+	// supplier 1 alone cannot cover the 6 units customer 1 now needs.
+	problem, _ = flownet.NewTransportation([]int64{5, 5}, []int64{4, 6}, [][]int64{{1, 3}, {2, 5}})
+	problem.ForbidLane(0, 1, true)
+	if err := problem.Solve(); !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("expected ErrInfeasible, found %v", err)
+	}
+	if expected := [][]int64{{4, 0}, {0, 5}}; !reflect.DeepEqual(problem.Shipments(), expected) {
+		t.Errorf("expected shipments %v meeting as much demand as possible, found %v", expected, problem.Shipments())
+	}
+}
+
+func TestTransportation_Errors(t *testing.T) {
+	if _, err := flownet.NewTransportation([]int64{1}, []int64{1}, [][]int64{{1, 2}}); err == nil {
+		t.Errorf("expected an error for a cost matrix with too many columns")
+	}
+	if _, err := flownet.NewTransportation([]int64{1, 2}, []int64{1}, [][]int64{{1}}); err == nil {
+		t.Errorf("expected an error for a cost matrix with too few rows")
+	}
+	if _, err := flownet.NewTransportation([]int64{-1}, []int64{1}, [][]int64{{1}}); err == nil {
+		t.Errorf("expected an error for a negative supply")
+	}
+	problem, _ := flownet.NewTransportation([]int64{1}, []int64{1}, [][]int64{{1}})
+	if err := problem.SetLaneCapacity(0, 1, 1); err == nil {
+		t.Errorf("expected an error for an unknown customer")
+	}
+	if err := problem.SetLaneCapacity(0, 0, -1); err == nil {
+		t.Errorf("expected an error for a negative capacity")
+	}
+	if err := problem.ForbidLane(1, 0, true); err == nil {
+		t.Errorf("expected an error for an unknown supplier")
+	}
+}
+
+func TestTransportation_CostOverflow(t *testing.T) {
+	// each lane is cheap enough on its own, but the two shipments together cost more than an int64 can hold.
+	problem, _ := flownet.NewTransportation([]int64{1, 1}, []int64{1, 1}, [][]int64{
+		{math.MaxInt64/2 + 1, math.MaxInt64/2 + 2},
+		{math.MaxInt64/2 + 2, math.MaxInt64/2 + 1},
+	})
+	if err := problem.Solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow, found %v", err)
+	}
+	if expected := [][]int64{{1, 0}, {0, 1}}; !reflect.DeepEqual(problem.Shipments(), expected) || problem.Cost() != 0 {
+		t.Errorf("expected shipments %v with no cost, found %v at cost %d", expected, problem.Shipments(), problem.Cost())
+	}
+
+	// a single shipment of three units overflows the cost of its lane.
+	problem, _ = flownet.NewTransportation([]int64{3}, []int64{3}, [][]int64{{math.MaxInt64 / 2}})
+	if err := problem.Solve(); !errors.Is(err, flownet.ErrCapacityOverflow) {
+		t.Errorf("expected ErrCapacityOverflow, found %v", err)
+	}
+}
+
+func TestTransportation_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		for _, short := range []bool{false, true} {
+			checkInstanceTransportation(t, path, instance, short)
+		}
+		return nil
+	})
+}
+
+// checkInstanceTransportation reports an error unless the cheapest shipments are found for a transportation
+// problem built from the provided instance. Each node is both a supplier and a customer, joined by a lane for
+// each edge of the instance; every other lane is forbidden. Half the lanes are limited to the capacity of their
+// edge.
+func checkInstanceTransportation(t *testing.T, path string, instance TestInstance, short bool) {
+	t.Helper()
+	n := instance.numNodes
+	supply, demand := make([]int64, n), make([]int64, n)
+	cost := make([][]int64, n)
+	for i := range cost {
+		cost[i] = make([]int64, n)
+		for j := range cost[i] {
+			cost[i][j] = int64((31*i+17*j)%23 - 5)
+		}
+	}
+	// each edge supplies its capacity and demands half of it, unless supplies are short, in which case
+	// edges leaving nodes with even IDs supply only half and every edge demands all of its capacity.
+	lanes := make(map[Edge]int64)
+	for _, e := range circulationEdges(instance) {
+		capacity := instance.capacities[e]
+		lanes[e] = flownet.Infinite
+		if (e.from+e.to)%2 == 0 {
+			lanes[e] = capacity
+		}
+		supply[e.from] += capacity
+		demand[e.to] += (capacity + 1) / 2
+		if short {
+			demand[e.to] += capacity / 2
+			if e.from%2 == 0 {
+				supply[e.from] -= capacity / 2
+			}
+		}
+	}
+	problem, err := flownet.NewTransportation(supply, demand, cost)
+	if err != nil {
+		t.Fatalf("%s: unexpected error: %v", path, err)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if capacity, ok := lanes[Edge{i, j}]; !ok {
+				problem.ForbidLane(i, j, true)
+			} else {
+				problem.SetLaneCapacity(i, j, capacity)
+			}
+		}
+	}
+
+	// the same problem as a flow network, in which customers follow suppliers.
+	capacities := make(map[Edge]int64)
+	required := int64(0)
+	for i := 0; i < n; i++ {
+		capacities[Edge{flownet.Source, i}] = supply[i]
+		capacities[Edge{n + i, flownet.Sink}] = demand[i]
+		required += demand[i]
+	}
+	for e, capacity := range lanes {
+		capacities[Edge{e.from, n + e.to}] = capacity
+	}
+	value, _ := maxFlow(capacities, flownet.Source, flownet.Sink)
+
+	err = problem.Solve()
+	if value < required && !errors.Is(err, flownet.ErrInfeasible) {
+		t.Errorf("%s: expected ErrInfeasible, found %v", path, err)
+	}
+	if value == required && err != nil {
+		t.Fatalf("%s: unexpected error: %v", path, err)
+	}
+
+	// the shipments must be a cheapest flow of the largest value possible.
+	shipments := problem.Shipments()
+	flows := make(map[Edge]int64)
+	for i := range shipments {
+		for j, amount := range shipments[i] {
+			if amount == 0 {
+				continue
+			}
+			if capacity, ok := lanes[Edge{i, j}]; !ok || amount < 0 || amount > capacity {
+				t.Errorf("%s: shipment of %d from %d to %d exceeds the capacity of its lane", path, amount, i, j)
+			}
+			flows[Edge{flownet.Source, i}] += amount
+			flows[Edge{n + j, flownet.Sink}] += amount
+			flows[Edge{i, n + j}] = amount
+		}
+	}
+	var arcs []costArc
+	total, shipped := int64(0), int64(0)
+	for e, capacity := range capacities {
+		flow, c := flows[e], int64(0)
+		if e.from >= 0 && e.to >= 0 {
+			c = cost[e.from][e.to-n]
+			total += flow * c
+		}
+		if e.to == flownet.Sink {
+			shipped += flow
+		}
+		if flow > capacity {
+			t.Errorf("%s: %d units along %v, which has capacity %d", path, flow, e, capacity)
+		}
+		arcs = residualArcs(arcs, e.from, e.to, flow, 0, capacity, c)
+	}
+	if shipped != value {
+		t.Errorf("%s: expected %d units to be shipped, found %d", path, value, shipped)
+	}
+	if total != problem.Cost() {
+		t.Errorf("%s: expected shipments to cost %d, found %d", path, total, problem.Cost())
+	}
+	if hasNegativeCycle(arcs) {
+		t.Errorf("%s: the shipments are not cheapest", path)
+	}
+}
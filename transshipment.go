@@ -1,16 +1,23 @@
 package flownet
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // A Transshipment is a circulation which allows flow to remain in a node without flowing out.
 // Each node has an amount of storage, with an associated minimum and maximum.
 // By default, every node in a Transshipment stores no extra flow.
 //
 // Transshipments can be used to model problems in which flow leaks or is consumed at certain
-// points in the network.
+// points in the network. Each unit of flow stored at a node may have a cost, set via SetStorageCost, which
+// CostScaling and NetworkSimplex take into account alongside the costs of edges set via SetEdgeCost.
 type Transshipment struct {
 	Circulation
-	bounds      map[int]bounds
+	// bounds maps from external nodeIDs to the bounds on the flow stored at each node.
+	bounds map[int]bounds
+	// storageCost maps from external nodeIDs to the cost per unit of flow stored at each node.
+	storageCost map[int]int64
 	specialNode int
 }
 
@@ -23,6 +30,7 @@ func NewTransshipment(numNodes int) Transshipment {
 	return Transshipment{
 		Circulation: NewCirculation(numNodes),
 		bounds:      make(map[int]bounds),
+		storageCost: make(map[int]int64),
 		specialNode: -1,
 	}
 }
@@ -35,25 +43,176 @@ func (t *Transshipment) SetNodeBounds(nodeID int, storageMin, storageMax int64)
 	if storageMax < storageMin {
 		return fmt.Errorf("storageMax cannot be smaller than storageMin: storageMin = %d, storageMax = %d", storageMin, storageMax)
 	}
-	t.bounds[nodeID+2] = bounds{storageMax, storageMin}
+	t.bounds[nodeID] = bounds{storageMax, storageMin}
 	return nil
 }
 
+// SetStorageCost sets the cost of storing one unit of flow at the provided node. Costs may be negative.
+// Storage costs are ignored when searching for any valid transshipment, but CostScaling and NetworkSimplex
+// find the cheapest one. An error is returned if nodeID is not a valid node ID.
+func (t *Transshipment) SetStorageCost(nodeID int, cost int64) error {
+	if nodeID < 0 || t.numNodes <= nodeID || nodeID == t.specialNode ||
+		t.hiddenNodes()[internalID(nodeID)] {
+		return fmt.Errorf("no node with ID %d is known", nodeID)
+	}
+	if cost == 0 {
+		delete(t.storageCost, nodeID)
+	} else {
+		t.storageCost[nodeID] = cost
+	}
+	return nil
+}
+
+// StorageCost returns the cost of storing one unit of flow at the provided node.
+func (t *Transshipment) StorageCost(nodeID int) int64 {
+	return t.storageCost[nodeID]
+}
+
 // NodeFlow returns the amount of flow stored at the provided node. The results are only meaningful
-// after PushRelabel has been run.
+// after PushRelabel has been run. After CostScaling or NetworkSimplex, these are the cheapest amounts to
+// store.
 func (t *Transshipment) NodeFlow(nodeID int) int64 {
 	return t.Circulation.Flow(nodeID, t.specialNode)
 }
 
-// PushRelabel finds a valid transshipment (if one exists) via the push-relabel algorithm.
+// PushRelabel finds a valid transshipment (if one exists) via the push-relabel algorithm. As with
+// Circulation.PushRelabel, use Solve to find out why no flow was found.
 func (t *Transshipment) PushRelabel() {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return
+	}
+	t.Circulation.PushRelabel()
+}
+
+// Solve finds a valid transshipment (if one exists) via the same algorithm as PushRelabel, returning an error if the
+// algorithm could not be completed. Use SatisfiesDemand to check whether a valid transshipment was found. Every
+// solver returns an error, and finds no flow, if the storage bounds of some node are negative.
+func (t *Transshipment) Solve() error {
+	return t.SolveContext(context.Background(), SolveOptions{})
+}
+
+// SolveContext finds a valid transshipment (if one exists) via the same algorithm as PushRelabel, stopping early
+// if the context is done. See FlowNetwork.SolveContext for details.
+func (t *Transshipment) SolveContext(ctx context.Context, opts SolveOptions) error {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.SolveContext(ctx, opts)
+}
+
+// Dinic finds a valid transshipment (if one exists) via Dinic's algorithm. Errors are returned as by Solve.
+func (t *Transshipment) Dinic() error {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.Dinic()
+}
+
+// PushRelabelHighestLabel finds a valid transshipment (if one exists) via the highest-label variant of the
+// push-relabel algorithm. Errors are returned as by Solve.
+func (t *Transshipment) PushRelabelHighestLabel() error {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.PushRelabelHighestLabel()
+}
+
+// BoykovKolmogorov finds a valid transshipment (if one exists) via the Boykov-Kolmogorov algorithm. Errors
+// are returned as by Solve.
+func (t *Transshipment) BoykovKolmogorov() error {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.BoykovKolmogorov()
+}
+
+// Resolve finds a valid transshipment (if one exists) starting from the flow found by the last solve. See
+// FlowNetwork.Resolve for details.
+func (t *Transshipment) Resolve() error {
+	if err := t.connectStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.Resolve()
+}
+
+// CostScaling finds the cheapest valid transshipment, if one exists, via the cost scaling algorithm. The
+// cost of a transshipment includes the cost of the flow stored at each node. See Circulation.CostScaling for
+// details.
+func (t *Transshipment) CostScaling() error {
+	if err := t.connectCheapestStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.CostScaling()
+}
+
+// NetworkSimplex finds the cheapest valid transshipment, if one exists, via the network simplex algorithm.
+// See Circulation.NetworkSimplex for details.
+func (t *Transshipment) NetworkSimplex() error {
+	if err := t.connectCheapestStorage(); err != nil {
+		t.clearFlow()
+		return err
+	}
+	return t.Circulation.NetworkSimplex()
+}
+
+// DecomposeFlow splits the transshipment found by the last solve into paths and cycles, as described for
+// Circulation.DecomposeFlow. Flow stored at a node is sent to the sink from that node.
+func (t *Transshipment) DecomposeFlow() []FlowPath {
+	hidden := t.hiddenNodes()
+	if t.specialNode != -1 {
+		hidden[internalID(t.specialNode)] = true
+	}
+	return decomposeFlow(t.numNodes+2, t.nodeFlows(hidden))
+}
+
+// connectStorage connects each node with storage bounds to a special node which absorbs stored flow. The
+// edge to the special node carries the cost of storage. An error is returned if the bounds of some node
+// cannot be used as the capacity and demand of its edge.
+func (t *Transshipment) connectStorage() error {
 	// N.B. a transshipment can be obtained from a circulation by adding fake edges
 	// to a new node that can store any flow that ends up being 'stored' at the nodes.
 	if t.specialNode == -1 {
 		t.specialNode = t.Circulation.AddNode()
 	}
 	for nodeID, bounds := range t.bounds {
-		t.Circulation.AddEdge(nodeID, t.specialNode, bounds.storageMax, bounds.storageMin)
+		if err := t.Circulation.AddEdge(nodeID, t.specialNode, bounds.storageMax, bounds.storageMin); err != nil {
+			return fmt.Errorf("could not connect the storage of node %d: %w", nodeID, err)
+		}
+		if err := t.Circulation.SetEdgeCost(nodeID, t.specialNode, t.storageCost[nodeID]); err != nil {
+			return err
+		}
 	}
-	t.Circulation.PushRelabel()
+	// the special node absorbs the flow which meets storage minimums; only the cost solvers let it absorb more.
+	if t.absorption == nil {
+		t.absorption = make(map[int]int64)
+	}
+	t.absorption[t.specialNode] = 0
+	return nil
+}
+
+// connectCheapestStorage connects storage as connectStorage does, and also lets the special node absorb
+// any flow stored beyond the minimum. The cost solvers can then trade stored flow against flow taken
+// straight from the supplies, and so find the cheapest amount to store. Solving for any valid transshipment
+// does not need this, so the other solvers only store the minimum.
+func (t *Transshipment) connectCheapestStorage() error {
+	if err := t.connectStorage(); err != nil {
+		return err
+	}
+	absorbed := int64(0)
+	for _, bounds := range t.bounds {
+		if bounds.storageMax == Infinite {
+			absorbed = Infinite
+		} else {
+			absorbed, _ = addCapacity(absorbed, bounds.storageMax-bounds.storageMin)
+		}
+	}
+	t.absorption[t.specialNode] = absorbed
+	return nil
 }
@@ -1,6 +1,7 @@
 package flownet_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/kalexmills/flownet"
@@ -30,3 +31,181 @@ func TestSanityCheckAllTransshipments(t *testing.T) {
 		return nil
 	})
 }
+
+func TestSolveAllTransshipments(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		graph := flownet.NewTransshipment(instance.numNodes)
+		for edge, cap := range instance.capacities {
+			if edge.from < 0 || edge.to < 0 {
+				continue
+			}
+			if err := graph.AddEdge(edge.from, edge.to, cap, 1); err != nil {
+				t.Error(err)
+			}
+		}
+		for i := 0; i < instance.numNodes; i++ {
+			graph.SetNodeBounds(i, 0, 3)
+		}
+		if err := graph.Solve(); err != nil {
+			t.Errorf("failed test %s: unexpected error from Solve: %v", path, err)
+			return nil
+		}
+		if err := flownet.SanityChecks.Transshipment(graph); err != nil {
+			t.Errorf("sanity checks failed in %s: %v", path, err)
+			return err
+		}
+		return nil
+	})
+}
+
+func TestTransshipment_StorageCosts(t *testing.T) {
+	// node 0 supplies up to 10 units. Node 3 needs 4 units, which must pass through node 1. Every other unit
+	// may be stored, at a profit, at node 1 or node 2; storing at node 2 is more profitable.
+	graph := flownet.NewTransshipment(4)
+	graph.AddEdge(0, 1, 10, 0)
+	graph.AddEdge(0, 2, 7, 0)
+	graph.AddEdge(1, 3, 10, 0)
+	graph.SetEdgeCost(0, 1, 1)
+	graph.SetEdgeCost(0, 2, 5)
+	graph.SetEdgeCost(1, 3, 1)
+	graph.SetNodeDemand(0, -10)
+	graph.SetNodeDemand(3, 4)
+	graph.SetNodeBounds(1, 0, 3)
+	graph.SetNodeBounds(2, 0, 10)
+	graph.SetStorageCost(1, -4)
+	graph.SetStorageCost(2, -10)
+	for name, solve := range map[string]func() error{
+		"CostScaling":    graph.CostScaling,
+		"NetworkSimplex": graph.NetworkSimplex,
+	} {
+		if err := solve(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if graph.NodeFlow(1) != 0 || graph.NodeFlow(2) != 6 {
+			t.Errorf("%s: expected to store 0 and 6 units, found %d and %d", name, graph.NodeFlow(1), graph.NodeFlow(2))
+		}
+		if graph.Cost() != 4*(1+1)+6*(5-10) {
+			t.Errorf("%s: expected a cost of -22, found %d", name, graph.Cost())
+		}
+		if err := flownet.SanityChecks.Transshipment(graph); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+
+	// once node 2 can store only 2 units, the rest goes to node 1, up to its bound.
+	graph.SetNodeBounds(2, 0, 2)
+	if err := graph.NetworkSimplex(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.NodeFlow(1) != 3 || graph.NodeFlow(2) != 2 {
+		t.Errorf("expected to store 3 and 2 units, found %d and %d", graph.NodeFlow(1), graph.NodeFlow(2))
+	}
+
+	// a minimum amount must be stored, however costly.
+	graph.SetStorageCost(1, 20)
+	graph.SetNodeBounds(1, 1, 3)
+	if err := graph.CostScaling(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if graph.NodeFlow(1) != 1 || graph.NodeFlow(2) != 2 {
+		t.Errorf("expected to store 1 and 2 units, found %d and %d", graph.NodeFlow(1), graph.NodeFlow(2))
+	}
+	if err := graph.SetStorageCost(10, 1); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+}
+
+func TestTransshipment_StorageCosts_Instances(t *testing.T) {
+	visitAllInstances(t, FlowInstances, func(t *testing.T, path string, instance TestInstance) error {
+		edges := circulationEdges(instance)
+		for _, name := range []string{"CostScaling", "NetworkSimplex"} {
+			graph := flownet.NewTransshipment(instance.numNodes)
+			graph.Circulation = instanceCirculation(instance)
+			// every fourth node may store a few units, some at a profit, and some must store at least one.
+			stored := make(map[int]storage)
+			for u := 0; u < instance.numNodes; u += 4 {
+				s := storage{min: int64(u % 3 / 2), max: 3, cost: int64(u%7 - 4)}
+				graph.SetNodeBounds(u, s.min, s.max)
+				graph.SetStorageCost(u, s.cost)
+				stored[u] = s
+			}
+			solve := graph.CostScaling
+			if name == "NetworkSimplex" {
+				solve = graph.NetworkSimplex
+			}
+			err := solve()
+			if !feasibleCirculation(&graph.Circulation, edges, instance.numNodes, stored) {
+				if !errors.Is(err, flownet.ErrInfeasible) {
+					t.Errorf("%s: %s: expected ErrInfeasible, found %v", path, name, err)
+				}
+				continue
+			}
+			if err != nil {
+				t.Fatalf("%s: %s: unexpected error: %v", path, name, err)
+			}
+			for u, s := range stored {
+				s.flow = graph.NodeFlow(u)
+				stored[u] = s
+			}
+			checkCheapestCirculation(t, path+": "+name, &graph.Circulation, edges, instance.numNodes, stored)
+			if err := flownet.SanityChecks.Transshipment(graph); err != nil {
+				t.Errorf("%s: %s: sanity checks failed: %v", path, name, err)
+			}
+		}
+		return nil
+	})
+}
+
+func TestTransshipment_NodeBounds(t *testing.T) {
+	// node 0 supplies 5 units and must store at least 2 of them itself; the rest may pass on to node 2.
+	for name, solve := range map[string]func(*flownet.Transshipment) error{
+		"PushRelabelHighestLabel": (*flownet.Transshipment).PushRelabelHighestLabel,
+		"Dinic":                   (*flownet.Transshipment).Dinic,
+		"BoykovKolmogorov":        (*flownet.Transshipment).BoykovKolmogorov,
+		"Resolve":                 (*flownet.Transshipment).Resolve,
+		"Solve":                   (*flownet.Transshipment).Solve,
+	} {
+		graph := flownet.NewTransshipment(3)
+		graph.AddEdge(0, 1, 5, 0)
+		graph.AddEdge(1, 2, 5, 0)
+		graph.SetNodeDemand(0, -5)
+		graph.SetNodeBounds(0, 2, 3)
+		graph.SetNodeBounds(2, 0, 0)
+		if err := solve(&graph); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if !graph.SatisfiesDemand() {
+			t.Errorf("%s: expected the demand to be satisfied", name)
+		}
+		if graph.NodeFlow(0) < 2 || graph.NodeFlow(0) > 3 || graph.NodeFlow(2) != 0 {
+			t.Errorf("%s: expected node 0 to store 2 or 3 units and node 2 none, found %d and %d", name, graph.NodeFlow(0), graph.NodeFlow(2))
+		}
+		if err := flownet.SanityChecks.Transshipment(graph); err != nil {
+			t.Errorf("%s: sanity checks failed: %v", name, err)
+		}
+	}
+}
+
+func TestTransshipment_InvalidStorageBounds(t *testing.T) {
+	// a negative storage minimum cannot become the demand of the edge which stores flow.
+	graph := flownet.NewTransshipment(2)
+	graph.AddEdge(0, 1, 5, 0)
+	graph.SetNodeBounds(1, -1, 5)
+	solvers := map[string]func() error{
+		"Solve":                   graph.Solve,
+		"Dinic":                   graph.Dinic,
+		"PushRelabelHighestLabel": graph.PushRelabelHighestLabel,
+		"BoykovKolmogorov":        graph.BoykovKolmogorov,
+		"Resolve":                 graph.Resolve,
+		"CostScaling":             graph.CostScaling,
+		"NetworkSimplex":          graph.NetworkSimplex,
+	}
+	for name, solve := range solvers {
+		if err := solve(); err == nil {
+			t.Errorf("%s: expected an error for a negative storage minimum", name)
+		}
+		if graph.Outflow() != 0 {
+			t.Errorf("%s: expected no flow to be found, found %d", name, graph.Outflow())
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package flownet
+
+import "fmt"
+
+// An UndirectedGraph is a graph whose edges have capacities but no direction; flow may cross an edge in
+// either direction, up to its capacity. Unlike a FlowNetwork, an UndirectedGraph has no source or sink.
+// Instead, it is used to answer questions about cuts between arbitrary pairs of nodes.
+type UndirectedGraph struct {
+	// numNodes is the number of nodes in the graph.
+	numNodes int
+	// capacity contains a map from each edge to its capacity. Edges are stored using external IDs, with
+	// from < to.
+	capacity map[edge]int64
+}
+
+// An UndirectedCut partitions the nodes of an UndirectedGraph into two sides. The IDs of the nodes on
+// each side are sorted in ascending order.
+type UndirectedCut struct {
+	// SideA and SideB contain the IDs of the nodes on either side of the cut.
+	SideA, SideB []int
+	// Capacity is the total capacity of the edges crossing the cut.
+	Capacity int64
+}
+
+// NewUndirectedGraph constructs a new graph with the provided number of nodes and no edges.
+func NewUndirectedGraph(numNodes int) UndirectedGraph {
+	return UndirectedGraph{
+		numNodes: numNodes,
+		capacity: make(map[edge]int64),
+	}
+}
+
+// AddNode adds a new node to the graph and returns its ID, which must be used in subsequent calls.
+func (g *UndirectedGraph) AddNode() int {
+	g.numNodes++
+	return g.numNodes - 1
+}
+
+// AddEdge sets the capacity of the edge between u and v. Adding an edge twice has no additional effect.
+// An error is returned if either u or v is not a valid node ID.
+func (g *UndirectedGraph) AddEdge(u, v int, capacity int64) error {
+	if u == v {
+		return fmt.Errorf("self-loops are not allowed, found one with %d -- %d", u, v)
+	}
+	if u < 0 || u >= g.numNodes {
+		return fmt.Errorf("no node with ID %d is known", u)
+	}
+	if v < 0 || v >= g.numNodes {
+		return fmt.Errorf("no node with ID %d is known", v)
+	}
+	if capacity < 0 {
+		return fmt.Errorf("capacities must be non-negative")
+	}
+	g.capacity[undirectedEdge(u, v)] = capacity
+	return nil
+}
+
+// Capacity returns the capacity of the edge between u and v.
+func (g UndirectedGraph) Capacity(u, v int) int64 {
+	return g.capacity[undirectedEdge(u, v)]
+}
+
+// undirectedEdge returns the key used to store the edge between u and v.
+func undirectedEdge(u, v int) edge {
+	if u > v {
+		u, v = v, u
+	}
+	return edge{u, v}
+}
+
+// cut returns the cut separating the nodes for which inSideA is true from the rest.
+func (g UndirectedGraph) cut(inSideA func(nodeID int) bool) UndirectedCut {
+	var result UndirectedCut
+	for u := 0; u < g.numNodes; u++ {
+		if inSideA(u) {
+			result.SideA = append(result.SideA, u)
+		} else {
+			result.SideB = append(result.SideB, u)
+		}
+	}
+	for e, capacity := range g.capacity {
+		if inSideA(e.from) != inSideA(e.to) {
+			result.Capacity, _ = addCapacity(result.Capacity, capacity)
+		}
+	}
+	return result
+}
+
+// minCut finds a minimum cut separating s from t via Dinic's algorithm. The returned cut has s in SideA,
+// which contains as few nodes as possible.
+func (g UndirectedGraph) minCut(s, t int) (UndirectedCut, error) {
+	fn := NewFlowNetwork(g.numNodes)
+	fn.AddEdge(Source, s, Infinite)
+	fn.AddEdge(t, Sink, Infinite)
+	for e, capacity := range g.capacity {
+		fn.AddUndirectedEdge(e.from, e.to, capacity)
+	}
+	if err := fn.prepare(); err != nil {
+		return UndirectedCut{}, err
+	}
+	fn.clearFlow()
+	fn.dinic()
+	reachable := fn.residualReachable(sourceID)
+	return g.cut(func(u int) bool { return reachable[internalID(u)] }), nil
+}
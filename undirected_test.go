@@ -0,0 +1,27 @@
+package flownet_test
+
+import (
+	"testing"
+
+	"github.com/kalexmills/flownet"
+)
+
+func TestUndirectedGraph_AddEdge(t *testing.T) {
+	graph := flownet.NewUndirectedGraph(2)
+	if err := graph.AddEdge(0, 0, 1); err == nil {
+		t.Errorf("expected an error for a self-loop")
+	}
+	if err := graph.AddEdge(0, 2, 1); err == nil {
+		t.Errorf("expected an error for an unknown node")
+	}
+	if err := graph.AddEdge(0, 1, -1); err == nil {
+		t.Errorf("expected an error for a negative capacity")
+	}
+	u := graph.AddNode()
+	if err := graph.AddEdge(u, 0, 3); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if graph.Capacity(0, u) != 3 || graph.Capacity(u, 0) != 3 {
+		t.Errorf("expected capacity of 3 in both directions, found %d and %d", graph.Capacity(0, u), graph.Capacity(u, 0))
+	}
+}
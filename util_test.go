@@ -8,9 +8,12 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"testing"
+
+	"github.com/kalexmills/flownet"
 )
 
 const FlowInstances = ".flow"
@@ -114,3 +117,173 @@ func max(x, y int) int {
 	}
 	return x
 }
+
+// maxFlow finds a maximum flow from s to t through the provided capacities via the Edmonds-Karp algorithm, and
+// returns its value along with the residual capacity between each pair of nodes joined by an edge. It shares no
+// code with the solvers, so it can be used to check their results. Edges with Infinite capacity are never used up;
+// if s can reach t along such edges alone, the flow returned is Infinite.
+func maxFlow(capacities map[Edge]int64, s, t int) (int64, map[Edge]int64) {
+	residual := make(map[Edge]int64, 2*len(capacities))
+	for e, c := range capacities {
+		if c > 0 {
+			residual[e] = addInfinite(residual[e], c)
+			residual[Edge{e.to, e.from}] += 0 // the reverse edge must be present, so flow can be undone.
+		}
+	}
+	neighbors := residualNeighbors(residual)
+	total := int64(0)
+	for {
+		parent := map[int]int{s: s}
+		queue := []int{s}
+		for len(queue) > 0 && !hasKey(parent, t) {
+			u := queue[0]
+			queue = queue[1:]
+			for _, v := range neighbors[u] {
+				if !hasKey(parent, v) && residual[Edge{u, v}] > 0 {
+					parent[v] = u
+					queue = append(queue, v)
+				}
+			}
+		}
+		if !hasKey(parent, t) {
+			return total, residual
+		}
+		amount := int64(flownet.Infinite)
+		for v := t; v != s; v = parent[v] {
+			if r := residual[Edge{parent[v], v}]; r < amount {
+				amount = r
+			}
+		}
+		if amount == flownet.Infinite {
+			return flownet.Infinite, residual
+		}
+		for v := t; v != s; v = parent[v] {
+			e := Edge{parent[v], v}
+			if residual[e] != flownet.Infinite {
+				residual[e] -= amount
+			}
+			residual[Edge{v, parent[v]}] = addInfinite(residual[Edge{v, parent[v]}], amount)
+		}
+		total += amount
+	}
+}
+
+// networkCapacities returns the capacity of each edge of the provided network, including those which join a node
+// to the source or sink. Edges joining a node to a terminal automatically are only present once a solver has run.
+func networkCapacities(graph flownet.FlowNetwork, numNodes int) map[Edge]int64 {
+	result := make(map[Edge]int64)
+	for u := flownet.Source; u < numNodes; u++ {
+		for v := flownet.Source; v < numNodes; v++ {
+			if c := graph.Capacity(u, v); u != v && c > 0 {
+				result[Edge{u, v}] = c
+			}
+		}
+	}
+	return result
+}
+
+// reachable returns the set of nodes which can be reached from s along edges with positive residual capacity.
+func reachable(residual map[Edge]int64, s int) map[int]bool {
+	neighbors := residualNeighbors(residual)
+	result := map[int]bool{s: true}
+	stack := []int{s}
+	for len(stack) > 0 {
+		u := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, v := range neighbors[u] {
+			if !result[v] && residual[Edge{u, v}] > 0 {
+				result[v] = true
+				stack = append(stack, v)
+			}
+		}
+	}
+	return result
+}
+
+// residualNeighbors lists the nodes joined to each node by an edge in residual, in order of their IDs.
+func residualNeighbors(residual map[Edge]int64) map[int][]int {
+	result := make(map[int][]int)
+	for e := range residual {
+		result[e.from] = append(result[e.from], e.to)
+	}
+	for _, list := range result {
+		sort.Ints(list)
+	}
+	return result
+}
+
+// splitNodes returns capacities in which each node u of the provided capacities, other than those in kept, is
+// split into an incoming half with ID u and an outgoing half with ID u + numNodes, joined by an edge with the
+// provided node capacity.
+func splitNodes(capacities map[Edge]int64, numNodes int, nodeCapacity int64, kept ...int) map[Edge]int64 {
+	isKept := make(map[int]bool)
+	for _, u := range kept {
+		isKept[u] = true
+	}
+	result := make(map[Edge]int64)
+	for e, c := range capacities {
+		from := e.from
+		if !isKept[from] {
+			from += numNodes
+		}
+		result[Edge{from, e.to}] = c
+	}
+	for u := 0; u < numNodes; u++ {
+		if !isKept[u] {
+			result[Edge{u, u + numNodes}] = nodeCapacity
+		}
+	}
+	return result
+}
+
+// A costArc is an edge of a residual network which has a cost.
+type costArc struct {
+	from, to int
+	cost     int64
+}
+
+// hasNegativeCycle reports whether the provided arcs form a cycle of negative total cost, via the Bellman-Ford
+// algorithm. A flow is a cheapest flow of its value exactly when its residual network has no such cycle.
+func hasNegativeCycle(arcs []costArc) bool {
+	dist := make(map[int]int64)
+	for _, a := range arcs {
+		dist[a.from], dist[a.to] = 0, 0
+	}
+	for i := 0; i <= len(dist); i++ {
+		changed := false
+		for _, a := range arcs {
+			if dist[a.from]+a.cost < dist[a.to] {
+				dist[a.to] = dist[a.from] + a.cost
+				changed = true
+			}
+		}
+		if !changed {
+			return false
+		}
+	}
+	return true
+}
+
+// residualArcs appends the arcs of the residual network left by sending flow from one node to another, along an
+// edge which has the provided lower and upper bounds and cost.
+func residualArcs(arcs []costArc, from, to int, flow, lower, upper, cost int64) []costArc {
+	if flow < upper {
+		arcs = append(arcs, costArc{from, to, cost})
+	}
+	if flow > lower {
+		arcs = append(arcs, costArc{to, from, -cost})
+	}
+	return arcs
+}
+
+func addInfinite(x, y int64) int64 {
+	if x > flownet.Infinite-y {
+		return flownet.Infinite
+	}
+	return x + y
+}
+
+func hasKey(m map[int]int, key int) bool {
+	_, ok := m[key]
+	return ok
+}
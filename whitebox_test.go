@@ -1,7 +1,10 @@
 package flownet
 
 import (
+	"context"
+	"errors"
 	"math"
+	"math/big"
 	"testing"
 )
 
@@ -52,7 +55,7 @@ func TestAddEdge_ManualSourceSink(t *testing.T) {
 		g := NewFlowNetwork(2)
 
 		for _, edge := range test.edgesToAdd {
-			err := g.AddEdge(edge[0], edge[1], 1)
+			_, err := g.AddEdge(edge[0], edge[1], 1)
 			if err != nil {
 				t.Errorf("test #%d: expected no error but found: %v", idx, err)
 			}
@@ -92,3 +95,100 @@ func TestAddNode_ManualSourceSink(t *testing.T) {
 		}
 	}
 }
+
+func TestDischarge_RelabelError(t *testing.T) {
+	g := NewFlowNetwork(3)
+	g.AddEdge(Source, 1, 1)
+	g.AddEdge(1, 2, 1)
+	g.AddEdge(2, Sink, 1)
+	g.prepare()
+	g.excess[internalID(0)] = 5 // node 0 is isolated, so its excess has nowhere to go.
+
+	err := g.discharge(g.compile(), internalID(0), newMonitor(context.Background(), SolveOptions{}, &g))
+	var relabelErr *RelabelError
+	if !errors.As(err, &relabelErr) {
+		t.Fatalf("expected a *RelabelError, found %v", err)
+	}
+	if relabelErr.NodeID != 0 || relabelErr.Excess != 5 {
+		t.Errorf("expected error to report node 0 with excess 5, found node %d with excess %d", relabelErr.NodeID, relabelErr.Excess)
+	}
+}
+
+func TestCompile_WriteBack(t *testing.T) {
+	g := NewFlowNetwork(3)
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(1, 0, 2)
+	g.AddEdge(1, 2, 4)
+	g.PushRelabel()
+	expected := make(map[edge]int64)
+	for e, flow := range g.preflow {
+		expected[e] = flow
+	}
+
+	a := g.compile()
+	for u := 0; u < a.numNodes(); u++ {
+		for arc := a.first[u]; arc < a.first[u+1]; arc++ {
+			rev := a.reverse[arc]
+			if int(a.head[rev]) != u || a.reverse[rev] != int32(arc) {
+				t.Errorf("arc %d from %d to %d is not paired with its reverse", arc, u, a.head[arc])
+			}
+			if a.residual[arc] != g.residual(edge{u, int(a.head[arc])}) {
+				t.Errorf("arc %d from %d to %d has residual %d, expected %d", arc, u, a.head[arc], a.residual[arc], g.residual(edge{u, int(a.head[arc])}))
+			}
+		}
+	}
+	g.writeBack(a)
+	for e, flow := range expected {
+		if g.preflow[e] != flow {
+			t.Errorf("edge from %d to %d had flow %d after write back, expected %d", e.from, e.to, g.preflow[e], flow)
+		}
+	}
+}
+
+func TestPrepare_VisitList(t *testing.T) {
+	g := NewFlowNetwork(3)
+	g.AddEdge(0, 1, 5)
+	g.AddEdge(1, 2, 4)
+	g.PushRelabel()
+	visitList := g.adjacencyVisitList
+	g.AddEdge(0, 1, 3) // the nodes are already adjacent, so the visit list is kept.
+	g.PushRelabel()
+	if &g.adjacencyVisitList[0] != &visitList[0] {
+		t.Errorf("expected the visit list to be kept when no pair of adjacent nodes changes")
+	}
+	if g.Outflow() != 3 {
+		t.Errorf("expected outflow of 3, found %d", g.Outflow())
+	}
+	g.AddEdge(0, 2, 2)
+	g.PushRelabel()
+	if &g.adjacencyVisitList[0] == &visitList[0] {
+		t.Errorf("expected the visit list to be rebuilt once new nodes are adjacent")
+	}
+	if g.Outflow() != 5 {
+		t.Errorf("expected outflow of 5, found %d", g.Outflow())
+	}
+}
+
+func TestParametricBreakpoint_Infinite(t *testing.T) {
+	g := NewFlowNetwork(1)
+	g.AddEdge(Source, 0, Infinite)
+	g.AddEdge(0, Sink, 5)
+	p := parametricSolver{g: &g}
+	sourceSide := []bool{true, false, false}
+	tests := []struct {
+		base, slope *big.Int
+		lambda      int64
+		expected    int64
+	}{
+		{big.NewInt(5), big.NewInt(2), 3, 11},
+		{big.NewInt(Infinite), big.NewInt(0), 0, Infinite},
+		{new(big.Int).Add(big.NewInt(Infinite), big.NewInt(Infinite)), big.NewInt(1), 2, Infinite},
+		{big.NewInt(1), big.NewInt(maxFiniteCapacity), 1, Infinite},
+	}
+	for idx, test := range tests {
+		bp := p.breakpoint(parametricCut{sourceSide: sourceSide, base: test.base, slope: test.slope}, test.lambda)
+		if bp.Flow != test.expected || bp.Cut.Capacity != test.expected {
+			t.Errorf("test #%d: expected flow and cut capacity %d, found %d and %d", idx, test.expected, bp.Flow, bp.Cut.Capacity)
+		}
+	}
+}